@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"dv/db"
+	"dv/internal"
+	"dv/internal/i18n"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// renderFlags are the output flags shared by every command that writes charts.
+type renderFlags struct {
+	assets    *string
+	format    *string
+	theme     *string
+	themeFile *string
+	locale    *string
+}
+
+func addRenderFlags(fs *flag.FlagSet) *renderFlags {
+	return &renderFlags{
+		assets:    fs.String("assets", string(internal.AssetsCDN), "where chart pages load ECharts from: cdn, inline or local"),
+		format:    fs.String("format", string(internal.FormatHTML), "chart output: interactive \"html\" pages or static \"svg\" images"),
+		theme:     fs.String("theme", internal.ThemeLight.Name, "chart theme: "+strings.Join(internal.ThemeNames(), ", ")),
+		themeFile: fs.String("theme-file", "", "load a custom JSON theme, overriding -theme"),
+		locale:    fs.String("locale", string(i18n.English), "language of chart titles, labels and numbers: en or ru"),
+	}
+}
+
+// options parses the flag values; invalid values exit with status 2.
+func (f *renderFlags) options() []internal.Option {
+	assets, err := internal.ParseAssetsMode(*f.assets)
+	if err != nil {
+		slog.Error("invalid -assets", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	outFormat, err := internal.ParseFormat(*f.format)
+	if err != nil {
+		slog.Error("invalid -format", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	chartTheme, err := internal.ParseTheme(*f.theme)
+	if err != nil {
+		slog.Error("invalid -theme", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	if *f.themeFile != "" {
+		if chartTheme, err = internal.LoadTheme(*f.themeFile); err != nil {
+			slog.Error("invalid -theme-file", slog.String("error", err.Error()))
+			os.Exit(2)
+		}
+	}
+	lang, err := i18n.ParseLocale(*f.locale)
+	if err != nil {
+		slog.Error("invalid -locale", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	return []internal.Option{
+		internal.WithAssets(assets),
+		internal.WithFormat(outFormat),
+		internal.WithTheme(chartTheme),
+		internal.WithLocale(lang),
+	}
+}
+
+// filterFlags narrow every chart to a slice of the catalog.
+type filterFlags struct {
+	yearFrom *int
+	yearTo   *int
+	genre    *string
+	country  *string
+	language *string
+	minVotes *int
+	minCount *int
+	top      *int
+	stopList *string
+}
+
+func addFilterFlags(fs *flag.FlagSet) *filterFlags {
+	return &filterFlags{
+		yearFrom: fs.Int("year-from", 0, "only movies released in or after this year"),
+		yearTo:   fs.Int("year-to", 0, "only movies released in or before this year (yearly trends stop at 2016 unless -year-from or -year-to is set)"),
+		genre:    fs.String("genre", "", "only movies of this genre"),
+		country:  fs.String("country", "", "only movies produced in this country (name or ISO code)"),
+		language: fs.String("language", "", "only movies in this language (name or code)"),
+		minVotes: fs.Int("min-votes", 0, "skip movies with fewer votes"),
+		minCount: fs.Int("min-count", 0, "skip studios, actors, genres, ... with fewer movies (default: per chart)"),
+		top:      fs.Int("top", 0, "rows in ranked charts such as top studios (default: per chart); the scatter and ROI histogram keep their full sample"),
+		stopList: fs.String("stop-keywords", strings.Join(db.DefaultStopKeywords, ","), "comma-separated keywords left out of the keyword charts (\"\" keeps all)"),
+	}
+}
+
+// filter returns the flag values as a db.Filter; invalid values exit with
+// status 2.
+func (f *filterFlags) filter() db.Filter {
+	filter := db.Filter{
+		YearFrom:     *f.yearFrom,
+		YearTo:       *f.yearTo,
+		Genre:        *f.genre,
+		Country:      *f.country,
+		Language:     *f.language,
+		MinVoteCount: *f.minVotes,
+		MinCount:     *f.minCount,
+		TopN:         *f.top,
+		StopKeywords: splitNames(*f.stopList),
+	}
+	if err := filter.Validate(); err != nil {
+		slog.Error("invalid filter", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	return filter
+}
+
+// sourceFlags choose where chart data comes from: Postgres, optionally behind
+// the query result cache, or fixture files.
+type sourceFlags struct {
+	fixtures *string
+	cache    *string
+	cacheDir *string
+	cacheTTL *time.Duration
+}
+
+func addSourceFlags(fs *flag.FlagSet) *sourceFlags {
+	return &sourceFlags{
+		fixtures: fs.String("fixtures", "", "read query results from the JSON fixture files in this directory instead of Postgres"),
+		cache:    fs.String("query-cache", "none", "cache query results: \"none\", in \"memory\" or on \"disk\""),
+		cacheDir: fs.String("query-cache-dir", ".cache/queries", "directory of the disk query cache"),
+		cacheTTL: fs.Duration("query-cache-ttl", time.Hour, "how long a cached query result is used"),
+	}
+}
+
+// source is an open data source.
+type source struct {
+	repo     db.Repository
+	cache    *db.Cache // nil unless -query-cache is set
+	maxConns int       // 0 for fixtures
+	close    func()
+}
+
+// open connects to Postgres or loads the fixtures; invalid flag values exit
+// with status 2.
+func (f *sourceFlags) open(ctx context.Context) (*source, error) {
+	if *f.fixtures != "" {
+		memory, err := db.LoadFixtures(*f.fixtures)
+		if err != nil {
+			slog.Error("invalid -fixtures", slog.String("error", err.Error()))
+			os.Exit(2)
+		}
+		return &source{repo: memory, close: func() {}}, nil
+	}
+	store := f.store()
+	postgres, err := connectPostgres(ctx)
+	if err != nil {
+		return nil, err
+	}
+	src := &source{repo: db.New(postgres.Pool()), maxConns: postgres.MaxConns(), close: postgres.Close}
+	if store != nil {
+		src.cache = db.NewCache(postgres.Pool(), store, *f.cacheTTL)
+		src.repo = db.New(src.cache)
+	}
+	return src, nil
+}
+
+// store is the configured cache backend, nil when caching is off.
+func (f *sourceFlags) store() db.CacheStore {
+	if *f.cache == "none" || *f.cache == "" {
+		return nil
+	}
+	if *f.cacheTTL <= 0 {
+		slog.Error("invalid -query-cache-ttl", slog.String("error", "must be positive"))
+		os.Exit(2)
+	}
+	switch *f.cache {
+	case "memory":
+		return db.NewMemoryStore()
+	case "disk":
+		return db.NewDirStore(*f.cacheDir)
+	}
+	slog.Error("invalid -query-cache", slog.String("error", fmt.Sprintf("unknown backend %q (want none, memory or disk)", *f.cache)))
+	os.Exit(2)
+	return nil
+}
+
+// workers caps n at the connection pool size; 0 means the pool size.
+func (s *source) workers(n int) int {
+	if s.maxConns > 0 && (n <= 0 || n > s.maxConns) {
+		return s.maxConns
+	}
+	return n
+}
+
+// registerSpecs adds the chart specs in dir to registry; invalid specs exit
+// with status 2.
+func registerSpecs(registry *internal.Registry, dir string) {
+	if dir == "" {
+		return
+	}
+	specs, err := internal.LoadSpecs(dir)
+	if err != nil {
+		slog.Error("invalid chart specs", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	if err := registry.RegisterSpecs(specs...); err != nil {
+		slog.Error("invalid chart specs", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+}
+
+func connectPostgres(ctx context.Context) (*db.Postgres, error) {
+	return db.NewPostgres(ctx, db.Config{
+		Host:     "localhost",
+		Port:     5432,
+		Dbname:   "movies",
+		Username: "postgres",
+		Password: "postgres",
+	})
+}
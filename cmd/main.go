@@ -5,34 +5,158 @@ import (
 	"dv/db"
 	"dv/internal"
 	"dv/pkg/logger"
+	"flag"
+	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "sql":
+			runSQL(os.Args[2:])
+			return
+		case "serve":
+			runServe(os.Args[2:])
+			return
+		}
+	}
+
+	list := flag.Bool("list", false, "list registered charts and exit")
+	only := flag.String("charts", "", "comma-separated chart names to generate (default: all enabled)")
+	enable := flag.String("enable", "", "comma-separated chart names to enable")
+	disable := flag.String("disable", "", "comma-separated chart names to disable")
+	workers := flag.Int("workers", 0, "max charts generated in parallel (default: pool size)")
+	timeout := flag.Duration("timeout", 0, "deadline for the whole run (0 = none)")
+	chartTimeout := flag.Duration("chart-timeout", 0, "deadline for each chart (0 = none)")
+	keepGoing := flag.Bool("continue", false, "keep generating after a chart fails and report all failures")
+	failOn := flag.String("fail-on", string(internal.FailOnAny), "exit non-zero on \"any\" failure or only on \"critical\" chart failures")
+	missingDates := flag.String("missing-dates", string(internal.MissingDatesExclude), "seasonality chart: \"exclude\" undated movies or show them as \"unknown\"")
+	scatterLog := flag.Bool("scatter-log", true, "scatter: logarithmic budget/revenue axes")
+	scatterOutliers := flag.Int("scatter-outliers", 10, "scatter: number of outlier movies to label")
+	decadesIndex := flag.Bool("decades-index", false, "decades: plot every metric as an index of the first decade (= 100)")
+	dashboard := flag.Bool("dashboard", true, "write dashboard.html and index.html after generating all charts")
+	render := addRenderFlags(flag.CommandLine)
+	filters := addFilterFlags(flag.CommandLine)
+	sources := addSourceFlags(flag.CommandLine)
+	invalidate := flag.String("invalidate", "", "comma-separated query names whose cached results are dropped before generating, or \"all\"")
+	specsDir := flag.String("specs", "", "directory of YAML/JSON chart specs to register alongside the built-in charts")
+	critical := flag.String("critical", "", "comma-separated chart names treated as critical")
+	flag.Parse()
+
 	logger.InitLogger("debug")
-	ctx := context.Background()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
 
-	postgres, err := db.NewPostgres(ctx, db.Config{
-		Host:     "localhost",
-		Port:     5432,
-		Dbname:   "movies",
-		Username: "postgres",
-		Password: "postgres",
-	})
+	registry := internal.DefaultRegistry().Clone()
+	registerSpecs(registry, *specsDir)
+	if err := registry.Enable(splitNames(*enable)...); err != nil {
+		slog.Error("invalid -enable", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	if err := registry.Disable(splitNames(*disable)...); err != nil {
+		slog.Error("invalid -disable", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	if err := registry.MarkCritical(splitNames(*critical)...); err != nil {
+		slog.Error("invalid -critical", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	policy, err := internal.ParseFailPolicy(*failOn)
+	if err != nil {
+		slog.Error("invalid -fail-on", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	datePolicy, err := internal.ParseMissingDatePolicy(*missingDates)
+	if err != nil {
+		slog.Error("invalid -missing-dates", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	if *scatterOutliers < 0 {
+		slog.Error("invalid -scatter-outliers", slog.String("error", fmt.Sprintf("must not be negative, got %d", *scatterOutliers)))
+		os.Exit(2)
+	}
+	renderOpts := render.options()
+	filter := filters.filter()
+	if *list {
+		for _, info := range registry.List() {
+			state := "enabled"
+			if info.Disabled {
+				state = "disabled"
+			}
+			if info.Critical {
+				state += ",critical"
+			}
+			fmt.Printf("%-18s %-14s %-17s %-24s %s\n", info.Name, info.ChartType, state, info.Source, info.Description)
+		}
+		return
+	}
 
+	src, err := sources.open(ctx)
 	if err != nil {
 		slog.Error("failed to connect to Postgres", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
-	defer postgres.Close()
-	queries := db.New(postgres.Pool())
+	defer src.close()
+	if src.cache != nil && *invalidate != "" {
+		if err := invalidateQueries(src.cache, *invalidate); err != nil {
+			slog.Error("failed to invalidate query cache", slog.String("error", err.Error()))
+			src.close()
+			os.Exit(1)
+		}
+	}
 
-	chartsService := internal.NewCharts(queries,"./charts/")
+	chartsService := internal.NewCharts(src.repo, "./charts/", append(renderOpts,
+		internal.WithWorkers(src.workers(*workers)),
+		internal.WithChartTimeout(*chartTimeout),
+		internal.WithContinueOnError(*keepGoing),
+		internal.WithMissingDates(datePolicy),
+		internal.WithDashboard(*dashboard),
+		internal.WithScatterOptions(internal.ScatterOptions{LogAxes: *scatterLog, Outliers: *scatterOutliers}),
+		internal.WithDecadeIndex(*decadesIndex),
+		internal.WithFilter(filter),
+		internal.WithRegistry(registry),
+	)...)
 
-	if err := chartsService.GenerateAllCharts(); err != nil {
-		slog.Error("failed to generate charts", slog.String("error", err.Error()))
+	if names := splitNames(*only); len(names) > 0 {
+		err = chartsService.Generate(ctx, names...)
+	} else {
+		err = chartsService.GenerateAllCharts(ctx)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to generate charts", slog.String("error", err.Error()))
+	}
+	if policy.Fails(err) {
+		src.close()
 		os.Exit(1)
 	}
 
 }
+
+// invalidateQueries drops the cached results of the comma-separated query
+// names, or of every query for "all".
+func invalidateQueries(cache *db.Cache, names string) error {
+	if names == "all" {
+		return cache.Invalidate()
+	}
+	return cache.Invalidate(splitNames(names)...)
+}
+
+func splitNames(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
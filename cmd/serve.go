@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"dv/internal"
+	"dv/internal/api"
+	"dv/pkg/logger"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// runServe implements "dv serve": every chart at its own URL, regenerated from
+// the database on request and cached for -cache-ttl, plus the live dashboard
+// and the JSON API under /api/v1/ (described at /api/v1/openapi.json).
+// POST /cache/invalidate[?query=A,B] drops cached query results and marks every
+// chart stale. It and ?refresh need the -admin-token, or a loopback client
+// without one.
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "listen address")
+	ttl := fs.Duration("cache-ttl", 5*time.Minute, "how long a rendered chart is served before it is regenerated (0 = every request)")
+	dir := fs.String("dir", "", "directory charts are rendered to (default: a temporary directory removed on exit)")
+	enable := fs.String("enable", "", "comma-separated chart names to enable")
+	disable := fs.String("disable", "", "comma-separated chart names to disable")
+	specsDir := fs.String("specs", "", "directory of YAML/JSON chart specs to serve alongside the built-in charts")
+	workers := fs.Int("workers", 0, "max charts regenerated in parallel for the dashboard (default: pool size)")
+	chartTimeout := fs.Duration("chart-timeout", 30*time.Second, "deadline for regenerating each chart or answering an API request (0 = none)")
+	adminToken := fs.String("admin-token", "", "bearer token required by POST /cache/invalidate and ?refresh (default: only loopback clients may use them)")
+	shutdownTimeout := fs.Duration("shutdown-timeout", 10*time.Second, "how long in-flight requests may finish after SIGINT/SIGTERM")
+	render := addRenderFlags(fs)
+	filters := addFilterFlags(fs)
+	sources := addSourceFlags(fs)
+	fs.Parse(args)
+
+	logger.InitLogger("debug")
+	registry := internal.DefaultRegistry().Clone()
+	registerSpecs(registry, *specsDir)
+	if err := registry.Enable(splitNames(*enable)...); err != nil {
+		slog.Error("invalid -enable", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	if err := registry.Disable(splitNames(*disable)...); err != nil {
+		slog.Error("invalid -disable", slog.String("error", err.Error()))
+		os.Exit(2)
+	}
+	renderOpts := render.options()
+	filter := filters.filter()
+
+	outDir := *dir
+	if outDir == "" {
+		tmp, err := os.MkdirTemp("", "dv-serve-")
+		if err != nil {
+			slog.Error("failed to create chart directory", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		defer os.RemoveAll(tmp)
+		outDir = tmp
+	}
+	if !strings.HasSuffix(outDir, "/") {
+		outDir += "/"
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	src, err := sources.open(ctx)
+	if err != nil {
+		slog.Error("failed to connect to Postgres", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer src.close()
+
+	chartsService := internal.NewCharts(src.repo, outDir, append(renderOpts,
+		internal.WithWorkers(src.workers(*workers)),
+		internal.WithChartTimeout(*chartTimeout),
+		internal.WithFilter(filter),
+		internal.WithRegistry(registry),
+	)...)
+
+	server := internal.NewServer(chartsService, *ttl, internal.WithRefreshAuth(internal.TokenOrLoopback(*adminToken)))
+	mux := http.NewServeMux()
+	mux.Handle("/api/", api.New(src.repo, api.WithTimeout(*chartTimeout)))
+	mux.HandleFunc("POST /cache/invalidate", adminOnly(*adminToken, func(w http.ResponseWriter, r *http.Request) {
+		// ?query=A,B drops those queries' cached results, no query drops all
+		if src.cache != nil {
+			if err := invalidateQueries(src.cache, r.URL.Query().Get("query")); err != nil {
+				slog.Error("failed to invalidate query cache", slog.String("error", err.Error()))
+				http.Error(w, "failed to invalidate query cache", http.StatusInternalServerError)
+				return
+			}
+		}
+		server.Invalidate()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	mux.Handle("/", server)
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("serving charts", slog.String("addr", *addr), slog.String("dir", outDir), slog.Duration("cache_ttl", *ttl))
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		slog.Error("server failed", slog.String("error", err.Error()))
+		src.close()
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+	// a second signal kills the process instead of waiting for the shutdown
+	stop()
+	slog.Info("shutting down", slog.Duration("timeout", *shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("failed to shut down cleanly", slog.String("error", err.Error()))
+	}
+}
+
+// adminOnly guards the endpoints that change server state with the same check
+// as ?refresh: the -admin-token, or a loopback client when there is none.
+func adminOnly(token string, h http.HandlerFunc) http.HandlerFunc {
+	authorized := internal.TokenOrLoopback(token)
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case authorized(r):
+			h(w, r)
+		case token != "":
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing or invalid bearer token", http.StatusUnauthorized)
+		default:
+			http.Error(w, "only local clients may call this without -admin-token", http.StatusForbidden)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminOnly(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	for _, tc := range []struct {
+		name, token, remote, auth string
+		want                      int
+	}{
+		{"loopback without token", "", "127.0.0.1:5000", "", http.StatusNoContent},
+		{"IPv6 loopback", "", "[::1]:5000", "", http.StatusNoContent},
+		{"remote without token", "", "203.0.113.7:5000", "", http.StatusForbidden},
+		{"remote with token", "s3cret", "203.0.113.7:5000", "Bearer s3cret", http.StatusNoContent},
+		{"wrong token", "s3cret", "203.0.113.7:5000", "Bearer guess", http.StatusUnauthorized},
+		{"not a bearer token", "s3cret", "203.0.113.7:5000", "s3cret", http.StatusUnauthorized},
+		{"token required from loopback too", "s3cret", "127.0.0.1:5000", "", http.StatusUnauthorized},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/cache/invalidate", nil)
+		r.RemoteAddr = tc.remote
+		if tc.auth != "" {
+			r.Header.Set("Authorization", tc.auth)
+		}
+		w := httptest.NewRecorder()
+		adminOnly(tc.token, ok)(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"dv/db"
+	"dv/internal"
+	"dv/pkg/logger"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+// runSQL implements "dv sql": chart the result of an ad-hoc query and save it
+// as CSV, without touching queries.sql or writing a chart method.
+//
+//	go run ./cmd sql -type hbar -y movies -limit 20 \
+//	    -q 'SELECT country_name, count(*) AS movies FROM ... GROUP BY 1 ORDER BY 2 DESC'
+func runSQL(args []string) {
+	fs := flag.NewFlagSet("sql", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: dv sql [flags] (-f file.sql | -q query | query)")
+		fs.PrintDefaults()
+	}
+	file := fs.String("f", "", "read the query from this SQL file")
+	inline := fs.String("q", "", "inline query")
+	chartType := fs.String("type", string(internal.SpecBar), "chart type: bar, hbar, line, pie or scatter")
+	x := fs.String("x", "", "category column, or x value column for scatter (default: inferred from column types)")
+	y := fs.String("y", "", "comma-separated value columns (default: the numeric columns)")
+	series := fs.String("series", "", "column whose distinct values become separate series")
+	color := fs.String("color", "", "scatter: numeric column mapped to a color scale")
+	label := fs.String("label", "", "scatter: column used as point names")
+	sortBy := fs.String("sort", "", "sort rows by column, append :desc for descending")
+	limit := fs.Int("limit", 0, "keep only the first N rows (0 = all)")
+	title := fs.String("title", "", "chart title (default: the chart name)")
+	name := fs.String("name", "", "chart name, used for the output files (default: the SQL file name or \"query\")")
+	out := fs.String("out", "./charts/", "output directory")
+	timeout := fs.Duration("timeout", 0, "deadline for the query (0 = none)")
+	render := addRenderFlags(fs)
+	fs.Parse(args)
+
+	logger.InitLogger("debug")
+	source, query := "query", *inline
+	switch {
+	case *file != "" && *inline != "":
+		slog.Error("invalid -q", slog.String("error", "-f and -q are mutually exclusive"))
+		os.Exit(2)
+	case *file != "":
+		data, err := os.ReadFile(*file)
+		if err != nil {
+			slog.Error("invalid -f", slog.String("error", err.Error()))
+			os.Exit(2)
+		}
+		source, query = *file, string(data)
+	case query == "":
+		query = strings.Join(fs.Args(), " ")
+	}
+	if strings.TrimSpace(query) == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	spec := internal.NewSQLSpec(source, query)
+	spec.Name = *name
+	if spec.Name == "" {
+		spec.Name = "query"
+		if *file != "" {
+			spec.Name = strings.TrimSuffix(filepath.Base(*file), filepath.Ext(*file))
+		}
+	}
+	spec.Title = *title
+	spec.Type = internal.SpecType(*chartType)
+	spec.X = *x
+	spec.Y = splitNames(*y)
+	spec.Series = *series
+	spec.Color = *color
+	spec.Label = *label
+	spec.Limit = *limit
+	spec.Output = spec.Name + ".html"
+	if *sortBy != "" {
+		field, order, _ := strings.Cut(*sortBy, ":")
+		spec.Sort = &internal.SpecSort{Field: field, Order: order}
+	}
+	renderOpts := render.options()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+	postgres, err := connectPostgres(ctx)
+	if err != nil {
+		slog.Error("failed to connect to Postgres", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer postgres.Close()
+	queries := db.New(postgres.Pool())
+
+	table, err := queries.QueryTable(ctx, query)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to run query", slog.String("error", err.Error()))
+		postgres.Close()
+		os.Exit(1)
+	}
+	columns := make([]string, len(table.Columns))
+	for i, fd := range table.Columns {
+		columns[i] = fd.Name + ":" + string(internal.KindOf(fd))
+	}
+	slog.InfoContext(ctx, "query done", slog.Int("rows", len(table.Rows)), slog.String("columns", strings.Join(columns, ", ")))
+
+	internal.InferMapping(spec, table)
+	if err := spec.Validate(); err != nil {
+		slog.Error("invalid chart mapping", slog.String("error", err.Error()))
+		postgres.Close()
+		os.Exit(2)
+	}
+	dir := *out
+	if !strings.HasSuffix(dir, "/") {
+		dir += "/"
+	}
+	chartsService := internal.NewCharts(queries, dir, renderOpts...)
+	n, err := chartsService.RenderSpec(spec, table)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to render chart", slog.String("error", err.Error()))
+		postgres.Close()
+		os.Exit(1)
+	}
+	if err := chartsService.WriteCSV(spec.Name+".csv", table); err != nil {
+		slog.ErrorContext(ctx, "failed to write CSV", slog.String("error", err.Error()))
+		postgres.Close()
+		os.Exit(1)
+	}
+	slog.InfoContext(ctx, "chart written",
+		slog.String("name", spec.Name),
+		slog.String("x", spec.X),
+		slog.String("y", strings.Join(spec.Y, ",")),
+		slog.Int("rows", n),
+		slog.String("dir", dir),
+	)
+}
@@ -0,0 +1,309 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache is a DBTX that answers Query and QueryRow from a CacheStore while the
+// stored result is younger than the TTL, so repeated chart runs and server
+// requests don't recompute the same aggregates. Wrap the pool with it before
+// handing it to New:
+//
+//	queries := db.New(db.NewCache(pool, db.NewMemoryStore(), 10*time.Minute))
+//
+// Results are keyed by the SQL and its arguments and stored as the raw column
+// bytes Postgres sent, so any query (sqlc, named or ad-hoc) is cached and
+// scans exactly as it would from the database. Exec always goes through.
+type Cache struct {
+	db      DBTX
+	store   CacheStore
+	ttl     time.Duration
+	typeMap *pgtype.Map
+	group   singleflight.Group
+	timeout time.Duration // bounds a shared fetch no caller can cancel
+
+	mu  sync.Mutex
+	gen uint64 // bumped by Invalidate; fetches started before it aren't stored
+}
+
+// fetchTimeout bounds a shared database round trip, which outlives the
+// callers that started it.
+const fetchTimeout = 5 * time.Minute
+
+// CacheStore keeps cached results. Keys have the form "<QueryName>/<hash>" so
+// a query's results can be dropped together.
+type CacheStore interface {
+	Get(key string) (*CacheEntry, error) // nil, nil when missing
+	Put(key string, e *CacheEntry) error
+	DeletePrefix(prefix string) error // "" deletes everything
+}
+
+// CacheEntry is one cached query result.
+type CacheEntry struct {
+	Query   string                    `json:"query"`
+	Fields  []pgconn.FieldDescription `json:"fields"`
+	Rows    [][][]byte                `json:"rows"` // raw values, nil for NULL
+	Tag     string                    `json:"tag"`
+	Created time.Time                 `json:"created"`
+	Expires time.Time                 `json:"expires"`
+}
+
+func NewCache(db DBTX, store CacheStore, ttl time.Duration) *Cache {
+	return &Cache{db: db, store: store, ttl: ttl, typeMap: pgtype.NewMap(), timeout: fetchTimeout}
+}
+
+func (c *Cache) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return c.db.Exec(ctx, sql, args...)
+}
+
+func (c *Cache) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	key, err := cacheKey(sql, args)
+	if err != nil {
+		return nil, err
+	}
+	e, err := c.store.Get(key)
+	if err != nil {
+		slog.WarnContext(ctx, "query cache read failed", slog.String("error", err.Error()))
+	}
+	if e != nil && time.Now().Before(e.Expires) {
+		slog.DebugContext(ctx, "query cache hit", slog.String("query", e.Query), slog.Duration("age", time.Since(e.Created)))
+		return c.replay(e), nil
+	}
+
+	// concurrent misses for the same key share one database round trip. It
+	// runs detached from the caller that started it, so one cancelled chart
+	// doesn't fail the others waiting on it; each waits only as long as its
+	// own context allows. The flight is keyed by the cache generation, so a
+	// miss after Invalidate doesn't join a fetch that started before it.
+	c.mu.Lock()
+	gen := c.gen
+	c.mu.Unlock()
+	ch := c.group.DoChan(fmt.Sprintf("%d/%s", gen, key), func() (any, error) {
+		shared, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+		defer cancel()
+		e, err := c.fetch(shared, sql, args)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.put(gen, key, e); err != nil {
+			slog.WarnContext(shared, "query cache write failed", slog.String("error", err.Error()))
+		}
+		return e, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return c.replay(res.Val.(*CacheEntry)), nil
+	}
+}
+
+func (c *Cache) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := c.Query(ctx, sql, args...)
+	return &cachedRow{rows: rows, err: err}
+}
+
+// Invalidate drops the cached results of the named queries, or of every query
+// when no names are given. Ad-hoc SQL without a sqlc name header is cached
+// under its first line.
+func (c *Cache) Invalidate(queries ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gen++
+	if len(queries) == 0 {
+		return c.store.DeletePrefix("")
+	}
+	var errs []error
+	for _, q := range queries {
+		errs = append(errs, c.store.DeletePrefix(cacheName(q)+"/"))
+	}
+	return errors.Join(errs...)
+}
+
+// put stores a result fetched in generation gen, unless Invalidate ran since:
+// the result may predate the change the invalidation was for.
+func (c *Cache) put(gen uint64, key string, e *CacheEntry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if gen != c.gen {
+		slog.Debug("query cache dropped a result fetched before invalidation", slog.String("query", e.Query))
+		return nil
+	}
+	return c.store.Put(key, e)
+}
+
+func (c *Cache) fetch(ctx context.Context, sql string, args []any) (*CacheEntry, error) {
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	e := &CacheEntry{
+		Query:  queryName(sql),
+		Fields: append([]pgconn.FieldDescription(nil), rows.FieldDescriptions()...),
+	}
+	for rows.Next() {
+		raw := rows.RawValues()
+		row := make([][]byte, len(raw))
+		for i, v := range raw {
+			if v != nil {
+				// RawValues is only valid until the next call to Next
+				row[i] = append([]byte{}, v...)
+			}
+		}
+		e.Rows = append(e.Rows, row)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	e.Tag = rows.CommandTag().String()
+	e.Created = time.Now()
+	e.Expires = e.Created.Add(c.ttl)
+	return e, nil
+}
+
+// cacheKey hashes the statement with its arguments, prefixed by the query name.
+func cacheKey(sql string, args []any) (string, error) {
+	h := sha256.New()
+	h.Write([]byte(sql))
+	for _, a := range args {
+		b, err := json.Marshal(a)
+		if err != nil {
+			return "", fmt.Errorf("failed to build cache key: %w", err)
+		}
+		fmt.Fprintf(h, "\x00%T\x00%s", a, b)
+	}
+	return cacheName(queryName(sql)) + "/" + hex.EncodeToString(h.Sum(nil)[:16]), nil
+}
+
+// cacheName makes a query name safe to use as a key prefix and directory name.
+func cacheName(name string) string {
+	b := []byte(name)
+	for i, ch := range b {
+		if !('a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9' || ch == '_') {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 64 {
+		b = b[:64]
+	}
+	return string(b)
+}
+
+func (c *Cache) replay(e *CacheEntry) *cachedRows {
+	return &cachedRows{entry: e, typeMap: c.typeMap, pos: -1}
+}
+
+// cachedRows replays a CacheEntry through the pgx.Rows interface, decoding
+// values with the same codecs pgx uses for live rows.
+type cachedRows struct {
+	entry   *CacheEntry
+	typeMap *pgtype.Map
+	pos     int
+	closed  bool
+	err     error
+}
+
+func (r *cachedRows) Close() { r.closed = true }
+
+func (r *cachedRows) Err() error { return r.err }
+
+func (r *cachedRows) CommandTag() pgconn.CommandTag { return pgconn.NewCommandTag(r.entry.Tag) }
+
+func (r *cachedRows) FieldDescriptions() []pgconn.FieldDescription { return r.entry.Fields }
+
+func (r *cachedRows) Next() bool {
+	if r.closed || r.err != nil || r.pos+1 >= len(r.entry.Rows) {
+		r.closed = true
+		return false
+	}
+	r.pos++
+	return true
+}
+
+func (r *cachedRows) Scan(dest ...any) error {
+	if len(dest) != len(r.entry.Fields) {
+		r.err = fmt.Errorf("number of field descriptions must equal number of destinations, got %d and %d", len(r.entry.Fields), len(dest))
+		return r.err
+	}
+	for i, d := range dest {
+		if d == nil {
+			continue
+		}
+		fd := r.entry.Fields[i]
+		if err := r.typeMap.Scan(fd.DataTypeOID, fd.Format, r.entry.Rows[r.pos][i], d); err != nil {
+			r.err = pgx.ScanArgError{ColumnIndex: i, FieldName: fd.Name, Err: err}
+			return r.err
+		}
+	}
+	return nil
+}
+
+func (r *cachedRows) Values() ([]any, error) {
+	values := make([]any, len(r.entry.Fields))
+	for i, fd := range r.entry.Fields {
+		raw := r.entry.Rows[r.pos][i]
+		if raw == nil {
+			continue
+		}
+		t, ok := r.typeMap.TypeForOID(fd.DataTypeOID)
+		if !ok {
+			if fd.Format == pgx.TextFormatCode {
+				values[i] = string(raw)
+			} else {
+				values[i] = append([]byte{}, raw...)
+			}
+			continue
+		}
+		v, err := t.Codec.DecodeValue(r.typeMap, fd.DataTypeOID, fd.Format, raw)
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (r *cachedRows) RawValues() [][]byte { return r.entry.Rows[r.pos] }
+
+func (r *cachedRows) Conn() *pgx.Conn { return nil }
+
+type cachedRow struct {
+	rows pgx.Rows
+	err  error
+}
+
+func (r *cachedRow) Scan(dest ...any) error {
+	if r.err != nil {
+		return r.err
+	}
+	defer r.rows.Close()
+	if !r.rows.Next() {
+		if err := r.rows.Err(); err != nil {
+			return err
+		}
+		return pgx.ErrNoRows
+	}
+	if err := r.rows.Scan(dest...); err != nil {
+		return err
+	}
+	return r.rows.Err()
+}
@@ -0,0 +1,133 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]*CacheEntry
+}
+
+// NewMemoryStore keeps cached results in the process; they are lost on exit.
+func NewMemoryStore() CacheStore {
+	return &memoryStore{entries: make(map[string]*CacheEntry)}
+}
+
+func (s *memoryStore) Get(key string) (*CacheEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entries[key], nil
+}
+
+// Put also drops the expired entries, so a long-running server keeps only
+// results it can still use.
+func (s *memoryStore) Put(key string, e *CacheEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for k, old := range s.entries {
+		if !now.Before(old.Expires) {
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = e
+	return nil
+}
+
+func (s *memoryStore) DeletePrefix(prefix string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.entries, key)
+		}
+	}
+	return nil
+}
+
+type dirStore struct {
+	dir string
+}
+
+// NewDirStore keeps cached results as JSON files under dir, one directory per
+// query, so they survive restarts and are shared by every run using the same
+// directory.
+func NewDirStore(dir string) CacheStore {
+	return &dirStore{dir: dir}
+}
+
+func (s *dirStore) path(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key)+".json")
+}
+
+func (s *dirStore) Get(key string) (*CacheEntry, error) {
+	b, err := os.ReadFile(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var e CacheEntry
+	if err := json.Unmarshal(b, &e); err != nil {
+		return nil, fmt.Errorf("failed to decode cache entry %s: %w", key, err)
+	}
+	return &e, nil
+}
+
+func (s *dirStore) Put(key string, e *CacheEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry %s: %w", key, err)
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// write then rename so concurrent readers never see a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *dirStore) DeletePrefix(prefix string) error {
+	// keys are "<query>/<hash>", so a query prefix is its directory; only
+	// entry files are removed in case dir is shared with anything else
+	query := strings.TrimSuffix(prefix, "/")
+	if query == "" {
+		query = "*"
+	}
+	files, err := filepath.Glob(filepath.Join(s.dir, query, "*.json"))
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("failed to clear query cache: %w", err)
+	}
+	return nil
+}
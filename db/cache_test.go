@@ -0,0 +1,308 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// fakeDB answers every query with the same two rows and counts the round
+// trips. When started is set, Query signals it and waits for release or the
+// end of its context.
+type fakeDB struct {
+	calls   atomic.Int32
+	started chan struct{}
+	release chan struct{}
+}
+
+func (d *fakeDB) Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.NewCommandTag("UPDATE 0"), nil
+}
+
+func (d *fakeDB) Query(ctx context.Context, sql string, _ ...interface{}) (pgx.Rows, error) {
+	d.calls.Add(1)
+	if d.started != nil {
+		d.started <- struct{}{}
+		select {
+		case <-d.release:
+		case <-ctx.Done():
+		}
+	}
+	// like pgx, give up on a cancelled context
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &cachedRows{entry: testEntry(sql), typeMap: pgtype.NewMap(), pos: -1}, nil
+}
+
+func (d *fakeDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, err := d.Query(ctx, sql, args...)
+	return &cachedRow{rows: rows, err: err}
+}
+
+func testEntry(sql string) *CacheEntry {
+	return &CacheEntry{
+		Query: queryName(sql),
+		Fields: []pgconn.FieldDescription{
+			{Name: "genre_name", DataTypeOID: pgtype.TextOID},
+			{Name: "movies_count", DataTypeOID: pgtype.Int8OID},
+		},
+		Rows: [][][]byte{{[]byte("Drama"), []byte("42")}, {nil, []byte("7")}},
+		Tag:  "SELECT 2",
+	}
+}
+
+const (
+	genresSQL  = "-- name: Genres :many\nSELECT genre_name, movies_count FROM genres WHERE year > $1"
+	studiosSQL = "-- name: Studios :many\nSELECT studio_name, movies_count FROM studios"
+)
+
+type genreCount struct {
+	name  pgtype.Text
+	count int64
+}
+
+// queryGenres runs sql through q and scans both columns.
+func queryGenres(ctx context.Context, q DBTX, sql string, args ...any) ([]genreCount, error) {
+	rows, err := q.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []genreCount
+	for rows.Next() {
+		var g genreCount
+		if err := rows.Scan(&g.name, &g.count); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
+
+func wantGenres(t *testing.T, got []genreCount, err error) {
+	t.Helper()
+	want := []genreCount{{pgtype.Text{String: "Drama", Valid: true}, 42}, {pgtype.Text{}, 7}}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("rows = %v, %v; want %v", got, err, want)
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDB{}
+	store := NewMemoryStore()
+	cache := NewCache(fake, store, time.Hour)
+	for range 3 {
+		got, err := queryGenres(ctx, cache, genresSQL, 2000)
+		wantGenres(t, got, err)
+	}
+	if n := fake.calls.Load(); n != 1 {
+		t.Fatalf("%d database calls for three identical queries, want 1", n)
+	}
+	// other arguments are another result
+	queryGenres(ctx, cache, genresSQL, 2010)
+	if n := fake.calls.Load(); n != 2 {
+		t.Fatalf("%d database calls, want 2 after new arguments", n)
+	}
+
+	key, _ := cacheKey(genresSQL, []any{2000})
+	e, _ := store.Get(key)
+	e.Expires = time.Now().Add(-time.Second)
+	got, err := queryGenres(ctx, cache, genresSQL, 2000)
+	wantGenres(t, got, err)
+	if n := fake.calls.Load(); n != 3 {
+		t.Errorf("%d database calls, want an expired entry refetched", n)
+	}
+
+	var count int64
+	if err := cache.QueryRow(ctx, studiosSQL).Scan(nil, &count); err != nil || count != 42 {
+		t.Errorf("QueryRow = %d, %v; want the first row", count, err)
+	}
+}
+
+func TestCacheInvalidate(t *testing.T) {
+	ctx := context.Background()
+	fake := &fakeDB{}
+	cache := NewCache(fake, NewMemoryStore(), time.Hour)
+	run := func() {
+		queryGenres(ctx, cache, genresSQL, 2000)
+		queryGenres(ctx, cache, genresSQL, 2010)
+		queryGenres(ctx, cache, studiosSQL)
+	}
+	run()
+	if n := fake.calls.Load(); n != 3 {
+		t.Fatalf("%d database calls, want 3", n)
+	}
+	// only the named query's results go, for every argument
+	if err := cache.Invalidate("Genres"); err != nil {
+		t.Fatal(err)
+	}
+	run()
+	if n := fake.calls.Load(); n != 5 {
+		t.Errorf("%d database calls after invalidating Genres, want 5", n)
+	}
+	if err := cache.Invalidate(); err != nil {
+		t.Fatal(err)
+	}
+	run()
+	if n := fake.calls.Load(); n != 8 {
+		t.Errorf("%d database calls after invalidating all, want 8", n)
+	}
+}
+
+func TestDirStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	fake := &fakeDB{}
+	got, err := queryGenres(ctx, NewCache(fake, NewDirStore(dir), time.Hour), genresSQL, 2000)
+	wantGenres(t, got, err)
+
+	// a fresh cache on the same directory, as in the next run, reads the file
+	store := NewDirStore(dir)
+	got, err = queryGenres(ctx, NewCache(fake, store, time.Hour), genresSQL, 2000)
+	wantGenres(t, got, err)
+	if n := fake.calls.Load(); n != 1 {
+		t.Errorf("%d database calls, want the second run served from disk", n)
+	}
+
+	key, _ := cacheKey(genresSQL, []any{2000})
+	e, err := store.Get(key)
+	if err != nil || e == nil {
+		t.Fatalf("Get(%s) = %v, %v", key, e, err)
+	}
+	want := testEntry(genresSQL)
+	if e.Query != "Genres" || e.Tag != want.Tag || !reflect.DeepEqual(e.Fields, want.Fields) || !reflect.DeepEqual(e.Rows, want.Rows) {
+		t.Errorf("round trip = %+v, want %+v", e, want)
+	}
+	if e.Expires.Sub(e.Created) != time.Hour {
+		t.Errorf("entry lives %v, want the TTL", e.Expires.Sub(e.Created))
+	}
+
+	if err := store.DeletePrefix("Studios/"); err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := store.Get(key); e == nil {
+		t.Error("deleting another query's entries removed Genres")
+	}
+	if err := store.DeletePrefix(""); err != nil {
+		t.Fatal(err)
+	}
+	if e, err := store.Get(key); e != nil || err != nil {
+		t.Errorf("Get after DeletePrefix(\"\") = %v, %v; want a miss", e, err)
+	}
+}
+
+func TestMemoryStoreEviction(t *testing.T) {
+	store := NewMemoryStore().(*memoryStore)
+	store.Put("A/1", &CacheEntry{Expires: time.Now().Add(-time.Minute)})
+	store.Put("A/2", &CacheEntry{Expires: time.Now().Add(time.Hour)})
+	store.Put("B/1", &CacheEntry{Expires: time.Now().Add(time.Hour)})
+	if _, ok := store.entries["A/1"]; ok || len(store.entries) != 2 {
+		t.Errorf("entries = %v, want the expired A/1 evicted", store.entries)
+	}
+}
+
+func TestCacheSharesConcurrentMisses(t *testing.T) {
+	fake := &fakeDB{started: make(chan struct{}, 1), release: make(chan struct{})}
+	cache := NewCache(fake, NewMemoryStore(), time.Hour)
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got, err := queryGenres(context.Background(), cache, genresSQL, 2000)
+			wantGenres(t, got, err)
+		}()
+	}
+	<-fake.started
+	time.Sleep(20 * time.Millisecond) // let the others pile up on the first
+	close(fake.release)
+	wg.Wait()
+	if n := fake.calls.Load(); n != 1 {
+		t.Errorf("%d database calls for 8 concurrent misses, want 1", n)
+	}
+}
+
+func TestCacheCancelledWaiter(t *testing.T) {
+	fake := &fakeDB{started: make(chan struct{}, 1), release: make(chan struct{})}
+	cache := NewCache(fake, NewMemoryStore(), time.Hour)
+
+	// the first caller starts the query, then gives up
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		_, err := queryGenres(ctx, cache, genresSQL, 2000)
+		first <- err
+	}()
+	<-fake.started
+	second := make(chan error)
+	go func() {
+		got, err := queryGenres(context.Background(), cache, genresSQL, 2000)
+		if err == nil {
+			wantGenres(t, got, err)
+		}
+		second <- err
+	}()
+	time.Sleep(20 * time.Millisecond) // let the second join the first
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("cancelled caller got %v, want context.Canceled", err)
+	}
+	close(fake.release)
+	if err := <-second; err != nil {
+		t.Errorf("waiting caller failed with the first caller's cancellation: %v", err)
+	}
+	if n := fake.calls.Load(); n != 1 {
+		t.Errorf("%d database calls, want 1", n)
+	}
+}
+
+func TestCacheInvalidateDuringFetch(t *testing.T) {
+	fake := &fakeDB{started: make(chan struct{}, 1), release: make(chan struct{})}
+	store := NewMemoryStore()
+	cache := NewCache(fake, store, time.Hour)
+	done := make(chan error)
+	go func() {
+		got, err := queryGenres(context.Background(), cache, genresSQL, 2000)
+		wantGenres(t, got, err)
+		done <- err
+	}()
+	<-fake.started
+	if err := cache.Invalidate("Genres"); err != nil {
+		t.Fatal(err)
+	}
+	close(fake.release)
+	<-done
+
+	// the caller got its rows, but the cache didn't keep the stale result
+	key, _ := cacheKey(genresSQL, []any{2000})
+	if e, _ := store.Get(key); e != nil {
+		t.Errorf("result fetched before Invalidate was cached: %+v", e)
+	}
+	fake.started = nil
+	queryGenres(context.Background(), cache, genresSQL, 2000)
+	if n := fake.calls.Load(); n != 2 {
+		t.Errorf("%d database calls, want the query refetched after Invalidate", n)
+	}
+}
+
+func TestCacheFetchTimeout(t *testing.T) {
+	fake := &fakeDB{started: make(chan struct{}, 1), release: make(chan struct{})}
+	defer close(fake.release)
+	cache := NewCache(fake, NewMemoryStore(), time.Hour)
+	cache.timeout = 20 * time.Millisecond
+	// the caller waits forever, but the stuck query doesn't
+	_, err := queryGenres(context.Background(), cache, genresSQL, 2000)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("stuck query = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,261 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Filter narrows the analytics queries to a slice of the catalog. Zero fields
+// are unset: the query then applies no filter or falls back to its built-in
+// default (e.g. YearlyTrends stops at 2016 unless a year bound is set,
+// StudioPerformance returns 15 rows).
+type Filter struct {
+	YearFrom     int      // first release year, inclusive
+	YearTo       int      // last release year, inclusive
+	Genre        string   // genre name, case-insensitive
+	Country      string   // production country name or ISO code, case-insensitive
+	Language     string   // language name or code, case-insensitive
+	MinVoteCount int      // skip movies with fewer votes
+	MinCount     int      // skip groups (genres, studios, actors, ...) with fewer movies
+	TopN         int      // row limit of ranked queries
+	StopKeywords []string // keywords left out of the keyword rankings, case-insensitive
+}
+
+// DefaultStopKeywords are TMDB tags about how a movie was made or released
+// rather than what it is about; they would top every keyword ranking. The CLI
+// and the API leave them out unless told otherwise.
+var DefaultStopKeywords = []string{"duringcreditsstinger", "aftercreditsstinger", "woman director", "independent film"}
+
+func (f Filter) Validate() error {
+	var errs []error
+	for _, v := range []struct {
+		name  string
+		value int
+	}{{"year from", f.YearFrom}, {"year to", f.YearTo}, {"min vote count", f.MinVoteCount}, {"min count", f.MinCount}, {"top n", f.TopN}} {
+		if v.value < 0 {
+			errs = append(errs, fmt.Errorf("%s must not be negative, got %d", v.name, v.value))
+		}
+	}
+	if f.YearFrom > 0 && f.YearTo > 0 && f.YearFrom > f.YearTo {
+		errs = append(errs, fmt.Errorf("year range %d-%d is empty", f.YearFrom, f.YearTo))
+	}
+	return errors.Join(errs...)
+}
+
+func optInt(v int) pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(v), Valid: v != 0}
+}
+
+func optText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+// lowerAll lowercases a list for case-insensitive matching; empty stays nil,
+// which the queries read as no list.
+func lowerAll(list []string) []string {
+	if len(list) == 0 {
+		return nil
+	}
+	out := make([]string, len(list))
+	for i, s := range list {
+		out[i] = strings.ToLower(s)
+	}
+	return out
+}
+
+func (f Filter) ActorRoleCountsParams() ActorRoleCountsParams {
+	return ActorRoleCountsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) CountUndatedMoviesParams() CountUndatedMoviesParams {
+	return CountUndatedMoviesParams{
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+	}
+}
+
+func (f Filter) CountryProductionStatsParams() CountryProductionStatsParams {
+	return CountryProductionStatsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) DecadeTrendsParams() DecadeTrendsParams {
+	return DecadeTrendsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+	}
+}
+
+func (f Filter) DirectorPerformanceParams() DirectorPerformanceParams {
+	return DirectorPerformanceParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) GenreAverageMetricsParams() GenreAverageMetricsParams {
+	return GenreAverageMetricsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) KeywordTrendsParams() KeywordTrendsParams {
+	return KeywordTrendsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		StopKeywords: lowerAll(f.StopKeywords),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) KeywordYearlyTrendsParams() KeywordYearlyTrendsParams {
+	return KeywordYearlyTrendsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		StopKeywords: lowerAll(f.StopKeywords),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) LanguagePopularityParams() LanguagePopularityParams {
+	return LanguagePopularityParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) ListTopProfitableMoviesParams() ListTopProfitableMoviesParams {
+	return ListTopProfitableMoviesParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) MonthlyReleasesParams() MonthlyReleasesParams {
+	return MonthlyReleasesParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+	}
+}
+
+func (f Filter) MovieNumericMetricsParams() MovieNumericMetricsParams {
+	return MovieNumericMetricsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+	}
+}
+
+func (f Filter) RuntimeSuccessSegmentsParams() RuntimeSuccessSegmentsParams {
+	return RuntimeSuccessSegmentsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+	}
+}
+
+func (f Filter) StudioPerformanceParams() StudioPerformanceParams {
+	return StudioPerformanceParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+		MinCount:     optInt(f.MinCount),
+		TopN:         optInt(f.TopN),
+	}
+}
+
+func (f Filter) YearlyTrendsParams() YearlyTrendsParams {
+	return YearlyTrendsParams{
+		YearFrom:     optInt(f.YearFrom),
+		YearTo:       optInt(f.YearTo),
+		Genre:        optText(f.Genre),
+		Country:      optText(f.Country),
+		Language:     optText(f.Language),
+		MinVoteCount: optInt(f.MinVoteCount),
+	}
+}
+
+// paramArgs lists a Params struct's fields as positional query arguments; sqlc
+// declares them in $1, $2, ... order.
+func paramArgs(params any) []any {
+	v := reflect.ValueOf(params)
+	args := make([]any, v.NumField())
+	for i := range args {
+		args[i] = v.Field(i).Interface()
+	}
+	return args
+}
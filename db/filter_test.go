@@ -0,0 +1,73 @@
+package db
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestFilterParams pins which queries a Filter's TopN and MinCount reach:
+// the ranked and grouped ones, never the movie-level row sets.
+func TestFilterParams(t *testing.T) {
+	f := Filter{YearFrom: 1990, Genre: "Drama", MinCount: 3, TopN: 7}
+	for name, want := range map[string]struct{ top, minCount bool }{
+		"ActorRoleCounts":         {true, true},
+		"CountUndatedMovies":      {false, false},
+		"CountryProductionStats":  {true, true},
+		"DecadeTrends":            {false, false},
+		"DirectorPerformance":     {true, true},
+		"GenreAverageMetrics":     {true, true},
+		"KeywordTrends":           {true, true},
+		"KeywordYearlyTrends":     {true, true},
+		"LanguagePopularity":      {true, true},
+		"ListTopProfitableMovies": {true, false},
+		"MonthlyReleases":         {false, false},
+		"MovieNumericMetrics":     {false, false},
+		"RuntimeSuccessSegments":  {false, false},
+		"StudioPerformance":       {true, true},
+		"YearlyTrends":            {false, false},
+	} {
+		m := reflect.ValueOf(f).MethodByName(name + "Params")
+		if !m.IsValid() {
+			t.Errorf("Filter has no %sParams", name)
+			continue
+		}
+		params := m.Call(nil)[0]
+		for _, field := range []struct {
+			name  string
+			want  bool
+			value int32
+		}{{"TopN", want.top, 7}, {"MinCount", want.minCount, 3}} {
+			v := params.FieldByName(field.name)
+			got := v.IsValid() && v.FieldByName("Valid").Bool()
+			if got != field.want {
+				t.Errorf("%s honours %s = %v, want %v", name, field.name, got, field.want)
+			} else if got && v.FieldByName("Int32").Int() != int64(field.value) {
+				t.Errorf("%s.%s = %v, want %d", name, field.name, v.Interface(), field.value)
+			}
+		}
+		// the other filters reach every query that declares them
+		if v := params.FieldByName("Genre"); v.IsValid() && !strings.EqualFold(v.FieldByName("String").String(), "drama") {
+			t.Errorf("%s dropped the genre", name)
+		}
+	}
+	if len(namedQueries) != 15 {
+		t.Errorf("%d named queries, want every one listed above", len(namedQueries))
+	}
+}
+
+// TestYearlyCap pins that the 2016 cap of the yearly queries only applies
+// without a year range, so -year-from 2018 still returns rows.
+func TestYearlyCap(t *testing.T) {
+	for name, sql := range map[string]string{
+		"YearlyTrends":        yearlyTrends,
+		"KeywordYearlyTrends": keywordYearlyTrends,
+	} {
+		if strings.Contains(sql, "COALESCE($2::int, 2016)") {
+			t.Errorf("%s caps year_to at 2016 even when year_from is set", name)
+		}
+		if !strings.Contains(sql, "$1::int IS NOT NULL OR $2::int IS NOT NULL OR EXTRACT(YEAR FROM m.release_date) <= 2016") {
+			t.Errorf("%s lost the 2016 default of the unfiltered run", name)
+		}
+	}
+}
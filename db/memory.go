@@ -0,0 +1,225 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Fixtures holds the canned result of every analytics query. LoadFixtures
+// reads each field from <field name>.json, a JSON array of rows using the
+// rows' json names (CountUndatedMovies is a plain number).
+type Fixtures struct {
+	ActorRoleCounts         []ActorRoleCountsRow
+	CountUndatedMovies      int64
+	CountryProductionStats  []CountryProductionStatsRow
+	DecadeTrends            []DecadeTrendsRow
+	DirectorPerformance     []DirectorPerformanceRow
+	GenreAverageMetrics     []GenreAverageMetricsRow
+	KeywordTrends           []KeywordTrendsRow
+	KeywordYearlyTrends     []KeywordYearlyTrendsRow
+	LanguagePopularity      []LanguagePopularityRow
+	ListTopProfitableMovies []ListTopProfitableMoviesRow
+	MonthlyReleases         []MonthlyReleasesRow
+	MovieNumericMetrics     []MovieNumericMetricsRow
+	RuntimeSuccessSegments  []RuntimeSuccessSegmentsRow
+	StudioPerformance       []StudioPerformanceRow
+	YearlyTrends            []YearlyTrendsRow
+}
+
+// Memory is a Repository answering every query from Fixtures, so charts can be
+// rendered and tested without a database. Rows are returned as they are: the
+// filter parameters are ignored.
+type Memory struct {
+	fixtures Fixtures
+}
+
+func NewMemory(f Fixtures) *Memory {
+	return &Memory{fixtures: f}
+}
+
+// LoadFixtures reads the fixture files in dir. Queries without a file return
+// no rows; files that match no query or have unknown columns are errors.
+func LoadFixtures(dir string) (*Memory, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	var f Fixtures
+	v := reflect.ValueOf(&f).Elem()
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || e.IsDir() {
+			continue
+		}
+		field := v.FieldByName(name)
+		if !field.IsValid() {
+			return nil, fmt.Errorf("fixture %s matches no query", e.Name())
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture %s: %w", e.Name(), err)
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(field.Addr().Interface()); err != nil {
+			return nil, fmt.Errorf("failed to decode fixture %s: %w", e.Name(), err)
+		}
+	}
+	return NewMemory(f), nil
+}
+
+func fixture[T any](ctx context.Context, rows []T) ([]T, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return slices.Clone(rows), nil
+}
+
+func (m *Memory) ActorRoleCounts(ctx context.Context, _ ActorRoleCountsParams) ([]ActorRoleCountsRow, error) {
+	return fixture(ctx, m.fixtures.ActorRoleCounts)
+}
+
+func (m *Memory) CountUndatedMovies(ctx context.Context, _ CountUndatedMoviesParams) (int64, error) {
+	return m.fixtures.CountUndatedMovies, ctx.Err()
+}
+
+func (m *Memory) CountryProductionStats(ctx context.Context, _ CountryProductionStatsParams) ([]CountryProductionStatsRow, error) {
+	return fixture(ctx, m.fixtures.CountryProductionStats)
+}
+
+func (m *Memory) DecadeTrends(ctx context.Context, _ DecadeTrendsParams) ([]DecadeTrendsRow, error) {
+	return fixture(ctx, m.fixtures.DecadeTrends)
+}
+
+func (m *Memory) DirectorPerformance(ctx context.Context, _ DirectorPerformanceParams) ([]DirectorPerformanceRow, error) {
+	return fixture(ctx, m.fixtures.DirectorPerformance)
+}
+
+func (m *Memory) GenreAverageMetrics(ctx context.Context, _ GenreAverageMetricsParams) ([]GenreAverageMetricsRow, error) {
+	return fixture(ctx, m.fixtures.GenreAverageMetrics)
+}
+
+func (m *Memory) KeywordTrends(ctx context.Context, _ KeywordTrendsParams) ([]KeywordTrendsRow, error) {
+	return fixture(ctx, m.fixtures.KeywordTrends)
+}
+
+func (m *Memory) KeywordYearlyTrends(ctx context.Context, _ KeywordYearlyTrendsParams) ([]KeywordYearlyTrendsRow, error) {
+	return fixture(ctx, m.fixtures.KeywordYearlyTrends)
+}
+
+func (m *Memory) LanguagePopularity(ctx context.Context, _ LanguagePopularityParams) ([]LanguagePopularityRow, error) {
+	return fixture(ctx, m.fixtures.LanguagePopularity)
+}
+
+func (m *Memory) ListTopProfitableMovies(ctx context.Context, _ ListTopProfitableMoviesParams) ([]ListTopProfitableMoviesRow, error) {
+	return fixture(ctx, m.fixtures.ListTopProfitableMovies)
+}
+
+func (m *Memory) MonthlyReleases(ctx context.Context, _ MonthlyReleasesParams) ([]MonthlyReleasesRow, error) {
+	return fixture(ctx, m.fixtures.MonthlyReleases)
+}
+
+func (m *Memory) MovieNumericMetrics(ctx context.Context, _ MovieNumericMetricsParams) ([]MovieNumericMetricsRow, error) {
+	return fixture(ctx, m.fixtures.MovieNumericMetrics)
+}
+
+func (m *Memory) RuntimeSuccessSegments(ctx context.Context, _ RuntimeSuccessSegmentsParams) ([]RuntimeSuccessSegmentsRow, error) {
+	return fixture(ctx, m.fixtures.RuntimeSuccessSegments)
+}
+
+func (m *Memory) StudioPerformance(ctx context.Context, _ StudioPerformanceParams) ([]StudioPerformanceRow, error) {
+	return fixture(ctx, m.fixtures.StudioPerformance)
+}
+
+func (m *Memory) YearlyTrends(ctx context.Context, _ YearlyTrendsParams) ([]YearlyTrendsRow, error) {
+	return fixture(ctx, m.fixtures.YearlyTrends)
+}
+
+// QueryTable answers the named queries (see NamedQuery) from the fixtures,
+// with the column types Postgres would report. Other SQL is an error.
+func (m *Memory) QueryTable(ctx context.Context, sql string, _ ...interface{}) (*Table, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	name := queryName(sql)
+	if _, ok := namedQueries[name]; !ok {
+		return nil, fmt.Errorf("in-memory repository only runs named queries, got %q", name)
+	}
+	field := reflect.ValueOf(m.fixtures).FieldByName(name)
+	if field.Kind() != reflect.Slice {
+		// CountUndatedMovies is a :one query with a single column
+		return &Table{
+			Columns: []pgconn.FieldDescription{{Name: "movies_count", DataTypeOID: pgtype.Int8OID}},
+			Rows:    [][]any{{field.Int()}},
+		}, nil
+	}
+	t := &Table{}
+	for _, f := range reflect.VisibleFields(field.Type().Elem()) {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		t.Columns = append(t.Columns, pgconn.FieldDescription{Name: name, DataTypeOID: fieldOID(f.Type)})
+	}
+	for i := range field.Len() {
+		row := field.Index(i)
+		values := make([]any, row.NumField())
+		for j := range values {
+			values[j] = cellValue(row.Field(j).Interface())
+		}
+		t.Rows = append(t.Rows, values)
+	}
+	return t, nil
+}
+
+// fieldOID is the Postgres type behind a generated row field.
+func fieldOID(t reflect.Type) uint32 {
+	switch t {
+	case reflect.TypeFor[pgtype.Int4](), reflect.TypeFor[int32]():
+		return pgtype.Int4OID
+	case reflect.TypeFor[pgtype.Int8](), reflect.TypeFor[int64](), reflect.TypeFor[int]():
+		return pgtype.Int8OID
+	case reflect.TypeFor[pgtype.Numeric]():
+		return pgtype.NumericOID
+	case reflect.TypeFor[float64]():
+		return pgtype.Float8OID
+	}
+	return pgtype.TextOID
+}
+
+// cellValue converts a row field to what pgx's Rows.Values returns for it,
+// nil for NULL.
+func cellValue(v any) any {
+	switch t := v.(type) {
+	case pgtype.Text:
+		if t.Valid {
+			return t.String
+		}
+		return nil
+	case pgtype.Int4:
+		if t.Valid {
+			return t.Int32
+		}
+		return nil
+	case pgtype.Int8:
+		if t.Valid {
+			return t.Int64
+		}
+		return nil
+	case pgtype.Numeric:
+		if t.Valid {
+			return t
+		}
+		return nil
+	case int:
+		return int64(t)
+	}
+	return v
+}
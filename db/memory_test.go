@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadFixtures(t *testing.T) {
+	m, err := LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := reflect.ValueOf(m.fixtures)
+	for i := range v.NumField() {
+		name, f := v.Type().Field(i).Name, v.Field(i)
+		if (f.Kind() == reflect.Slice && f.Len() == 0) || f.IsZero() {
+			t.Errorf("fixture %s is empty", name)
+		}
+	}
+
+	rows, err := m.YearlyTrends(context.Background(), YearlyTrendsParams{})
+	if err != nil || len(rows) != len(m.fixtures.YearlyTrends) {
+		t.Fatalf("YearlyTrends = %d rows, %v; want %d rows", len(rows), err, len(m.fixtures.YearlyTrends))
+	}
+	// callers own the returned rows
+	rows[0].Year = -1
+	if m.fixtures.YearlyTrends[0].Year == -1 {
+		t.Error("YearlyTrends returned the fixture slice itself")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := m.YearlyTrends(ctx, YearlyTrendsParams{}); err == nil {
+		t.Error("YearlyTrends with a cancelled context succeeded")
+	}
+}
+
+func TestLoadFixturesErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, file, body, want string
+	}{
+		{"bad JSON", "YearlyTrends.json", `[{"year": 2000,`, "failed to decode fixture YearlyTrends.json"},
+		{"wrong type", "YearlyTrends.json", `{"year": 2000}`, "failed to decode fixture YearlyTrends.json"},
+		{"unknown column", "YearlyTrends.json", `[{"year": 2000, "budget": 1}]`, `unknown field "budget"`},
+		{"unknown query", "Trends.json", `[]`, "fixture Trends.json matches no query"},
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, tc.file), []byte(tc.body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFixtures(dir); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: LoadFixtures = %v, want error containing %q", tc.name, err, tc.want)
+		}
+	}
+
+	if _, err := LoadFixtures(filepath.Join(t.TempDir(), "missing")); err == nil || !strings.Contains(err.Error(), "failed to read fixtures") {
+		t.Errorf("missing dir: LoadFixtures = %v, want a read error", err)
+	}
+	// directories are skipped, even when named like a fixture
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "YearlyTrends.json"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadFixtures(dir); err != nil {
+		t.Errorf("LoadFixtures with a fixture-named directory = %v, want no error", err)
+	}
+}
+
+func TestMemoryQueryTable(t *testing.T) {
+	m, err := LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	sql, args, ok := NamedQuery("LanguagePopularity", Filter{})
+	if !ok {
+		t.Fatal("LanguagePopularity is not a named query")
+	}
+	table, err := m.QueryTable(ctx, sql, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(table.ColumnNames(), ","); got != "language_role,language_name,movies_count,avg_rating,avg_revenue,avg_popularity" {
+		t.Errorf("columns = %s", got)
+	}
+	if len(table.Rows) != len(m.fixtures.LanguagePopularity) {
+		t.Errorf("rows = %d, want %d", len(table.Rows), len(m.fixtures.LanguagePopularity))
+	}
+	if v, ok := table.Rows[0][table.Column("movies_count")].(int64); !ok || v != m.fixtures.LanguagePopularity[0].MoviesCount {
+		t.Errorf("movies_count = %#v, want int64 %d", table.Rows[0][2], m.fixtures.LanguagePopularity[0].MoviesCount)
+	}
+
+	sql, _, _ = NamedQuery("CountUndatedMovies", Filter{})
+	if table, err = m.QueryTable(ctx, sql); err != nil || len(table.Rows) != 1 || table.Rows[0][0] != m.fixtures.CountUndatedMovies {
+		t.Errorf("CountUndatedMovies = %v, %v; want one row of %d", table, err, m.fixtures.CountUndatedMovies)
+	}
+
+	for _, sql := range []string{
+		"SELECT 1",
+		"-- name: DropEverything :exec\nDROP TABLE movie",
+		"",
+	} {
+		if _, err := m.QueryTable(ctx, sql); err == nil || !strings.Contains(err.Error(), "only runs named queries") {
+			t.Errorf("QueryTable(%q) = %v, want it rejected", sql, err)
+		}
+	}
+}
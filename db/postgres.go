@@ -22,7 +22,12 @@ type Postgres struct {
 func NewPostgres(ctx context.Context, cfg Config) (*Postgres, error) {
 	port := strconv.Itoa(int(cfg.Port))
 	dsn := "postgres://" + cfg.Username + ":" + cfg.Password + "@" + cfg.Host + ":" + port + "/" + cfg.Dbname + ""
-	pool, err := pgxpool.New(ctx, dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	poolCfg.ConnConfig.Tracer = queryTracer{}
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, err
 	}
@@ -40,3 +45,9 @@ func (p *Postgres) Close() {
 func (p *Postgres) Pool() *pgxpool.Pool {
 	return p.pool
 }
+
+// MaxConns is the pool's connection limit; callers running queries in
+// parallel should not use more workers than this.
+func (p *Postgres) MaxConns() int {
+	return int(p.pool.Config().MaxConns)
+}
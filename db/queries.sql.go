@@ -22,15 +22,53 @@ FROM person p
     JOIN movie m ON mc.movie_id = m.movie_id
 WHERE
     m.vote_average > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
     p.person_id,
     p.person_name
 HAVING
-    COUNT(mc.movie_id) >= 5
+    COUNT(mc.movie_id) >= COALESCE($7::int, 5)
 ORDER BY roles_count DESC, avg_movie_rating DESC
-LIMIT 20
+LIMIT COALESCE($8::int, 20)
 `
 
+type ActorRoleCountsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type ActorRoleCountsRow struct {
 	PersonName         pgtype.Text `json:"person_name"`
 	RolesCount         int64       `json:"roles_count"`
@@ -39,8 +77,17 @@ type ActorRoleCountsRow struct {
 }
 
 // Actors with highest number of roles and average rating of their movies
-func (q *Queries) ActorRoleCounts(ctx context.Context) ([]ActorRoleCountsRow, error) {
-	rows, err := q.db.Query(ctx, actorRoleCounts)
+func (q *Queries) ActorRoleCounts(ctx context.Context, arg ActorRoleCountsParams) ([]ActorRoleCountsRow, error) {
+	rows, err := q.db.Query(ctx, actorRoleCounts,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.MinCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -64,9 +111,62 @@ func (q *Queries) ActorRoleCounts(ctx context.Context) ([]ActorRoleCountsRow, er
 	return items, nil
 }
 
+const countUndatedMovies = `-- name: CountUndatedMovies :one
+SELECT COUNT(*) AS movies_count
+FROM movie m
+WHERE
+    m.release_date IS NULL
+    AND ($1::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($1::text)
+    ))
+    AND ($2::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($2::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($3::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($4::int IS NULL OR m.vote_count >= $4::int)
+`
+
+type CountUndatedMoviesParams struct {
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+}
+
+// Number of movies without a release date
+func (q *Queries) CountUndatedMovies(ctx context.Context, arg CountUndatedMoviesParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countUndatedMovies,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+	)
+	var movies_count int64
+	err := row.Scan(&movies_count)
+	return movies_count, err
+}
+
 const countryProductionStats = `-- name: CountryProductionStats :many
 SELECT
     c.country_name,
+    c.country_iso_code,
     COUNT(m.movie_id) as movies_count,
     ROUND(AVG(m.budget), 0) as avg_budget,
     ROUND(AVG(m.revenue), 0) as avg_revenue,
@@ -77,25 +177,75 @@ FROM country c
 WHERE
     m.budget > 0
     AND m.revenue > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
     c.country_id,
-    c.country_name
+    c.country_name,
+    c.country_iso_code
 HAVING
-    COUNT(m.movie_id) >= 10
+    COUNT(m.movie_id) >= COALESCE($7::int, 10)
 ORDER BY movies_count DESC
+LIMIT $8::int
 `
 
+type CountryProductionStatsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type CountryProductionStatsRow struct {
-	CountryName pgtype.Text `json:"country_name"`
-	MoviesCount int64       `json:"movies_count"`
-	AvgBudget   float64     `json:"avg_budget"`
-	AvgRevenue  float64     `json:"avg_revenue"`
-	AvgRating   float64     `json:"avg_rating"`
+	CountryName    pgtype.Text `json:"country_name"`
+	CountryIsoCode pgtype.Text `json:"country_iso_code"`
+	MoviesCount    int64       `json:"movies_count"`
+	AvgBudget      float64     `json:"avg_budget"`
+	AvgRevenue     float64     `json:"avg_revenue"`
+	AvgRating      float64     `json:"avg_rating"`
 }
 
 // Geography of film production and average metrics
-func (q *Queries) CountryProductionStats(ctx context.Context) ([]CountryProductionStatsRow, error) {
-	rows, err := q.db.Query(ctx, countryProductionStats)
+func (q *Queries) CountryProductionStats(ctx context.Context, arg CountryProductionStatsParams) ([]CountryProductionStatsRow, error) {
+	rows, err := q.db.Query(ctx, countryProductionStats,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.MinCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -105,6 +255,7 @@ func (q *Queries) CountryProductionStats(ctx context.Context) ([]CountryProducti
 		var i CountryProductionStatsRow
 		if err := rows.Scan(
 			&i.CountryName,
+			&i.CountryIsoCode,
 			&i.MoviesCount,
 			&i.AvgBudget,
 			&i.AvgRevenue,
@@ -125,32 +276,64 @@ SELECT
     FLOOR(
         EXTRACT(
             YEAR
-            FROM release_date
+            FROM m.release_date
         ) / 10
     ) * 10 as decade,
     COUNT(*) as movies_count,
-    ROUND(AVG(budget), 0) as avg_budget,
-    ROUND(AVG(revenue), 0) as avg_revenue,
-    ROUND(AVG(vote_average), 2) as avg_rating,
-    ROUND(AVG(runtime), 0) as avg_runtime
-FROM movie
+    ROUND(AVG(m.budget), 0) as avg_budget,
+    ROUND(AVG(m.revenue), 0) as avg_revenue,
+    ROUND(AVG(m.vote_average), 2) as avg_rating,
+    ROUND(AVG(m.runtime), 0) as avg_runtime
+FROM movie m
 
 WHERE
-    release_date IS NOT NULL
-    AND EXTRACT(
-        YEAR
-        FROM release_date
-    ) >= 1970
+    m.release_date IS NOT NULL
+    AND EXTRACT(YEAR FROM m.release_date) >= COALESCE($1::int, 1970)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
     FLOOR(
         EXTRACT(
             YEAR
-            FROM release_date
+            FROM m.release_date
         ) / 10
     ) * 10
 ORDER BY decade
 `
 
+type DecadeTrendsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+}
+
 type DecadeTrendsRow struct {
 	Decade      int     `json:"decade"`
 	MoviesCount int64   `json:"movies_count"`
@@ -161,8 +344,15 @@ type DecadeTrendsRow struct {
 }
 
 // Number of movies and average metrics by decades
-func (q *Queries) DecadeTrends(ctx context.Context) ([]DecadeTrendsRow, error) {
-	rows, err := q.db.Query(ctx, decadeTrends)
+func (q *Queries) DecadeTrends(ctx context.Context, arg DecadeTrendsParams) ([]DecadeTrendsRow, error) {
+	rows, err := q.db.Query(ctx, decadeTrends,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -205,15 +395,53 @@ WHERE
     AND mc.job = 'Director'
     AND m.revenue > 0
     AND m.vote_average > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
     p.person_id,
     p.person_name
 HAVING
-    COUNT(m.movie_id) >= 3
+    COUNT(m.movie_id) >= COALESCE($7::int, 3)
 ORDER BY avg_rating DESC, total_box_office DESC
-LIMIT 15
+LIMIT COALESCE($8::int, 15)
 `
 
+type DirectorPerformanceParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type DirectorPerformanceRow struct {
 	DirectorName   pgtype.Text `json:"director_name"`
 	DirectedMovies int64       `json:"directed_movies"`
@@ -224,8 +452,17 @@ type DirectorPerformanceRow struct {
 }
 
 // Top directors by average metrics of their movies
-func (q *Queries) DirectorPerformance(ctx context.Context) ([]DirectorPerformanceRow, error) {
-	rows, err := q.db.Query(ctx, directorPerformance)
+func (q *Queries) DirectorPerformance(ctx context.Context, arg DirectorPerformanceParams) ([]DirectorPerformanceRow, error) {
+	rows, err := q.db.Query(ctx, directorPerformance,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.MinCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -263,12 +500,53 @@ FROM genre g
     JOIN movie m ON mg.movie_id = m.movie_id
 WHERE
     m.vote_average > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
     g.genre_id,
     g.genre_name
+HAVING
+    COUNT(m.movie_id) >= COALESCE($7::int, 1)
 ORDER BY avg_rating DESC
+LIMIT $8::int
 `
 
+type GenreAverageMetricsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type GenreAverageMetricsRow struct {
 	GenreName     pgtype.Text `json:"genre_name"`
 	MoviesCount   int64       `json:"movies_count"`
@@ -278,8 +556,17 @@ type GenreAverageMetricsRow struct {
 }
 
 // Analysis of genres by average metrics
-func (q *Queries) GenreAverageMetrics(ctx context.Context) ([]GenreAverageMetricsRow, error) {
-	rows, err := q.db.Query(ctx, genreAverageMetrics)
+func (q *Queries) GenreAverageMetrics(ctx context.Context, arg GenreAverageMetricsParams) ([]GenreAverageMetricsRow, error) {
+	rows, err := q.db.Query(ctx, genreAverageMetrics,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.MinCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -315,15 +602,55 @@ FROM keyword k
     JOIN movie m ON mk.movie_id = m.movie_id
 WHERE
     m.vote_average > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
+    AND ($7::text[] IS NULL OR lower(k.keyword_name) <> ALL($7::text[]))
 GROUP BY
     k.keyword_id,
     k.keyword_name
 HAVING
-    COUNT(m.movie_id) >= 10
+    COUNT(m.movie_id) >= COALESCE($8::int, 10)
 ORDER BY movies_count DESC, avg_rating DESC
-LIMIT 20
+LIMIT COALESCE($9::int, 20)
 `
 
+type KeywordTrendsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	StopKeywords []string    `json:"stop_keywords"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type KeywordTrendsRow struct {
 	KeywordName pgtype.Text `json:"keyword_name"`
 	MoviesCount int64       `json:"movies_count"`
@@ -332,8 +659,18 @@ type KeywordTrendsRow struct {
 }
 
 // TOPIC 9: KEYWORDS AND TRENDS
-func (q *Queries) KeywordTrends(ctx context.Context) ([]KeywordTrendsRow, error) {
-	rows, err := q.db.Query(ctx, keywordTrends)
+func (q *Queries) KeywordTrends(ctx context.Context, arg KeywordTrendsParams) ([]KeywordTrendsRow, error) {
+	rows, err := q.db.Query(ctx, keywordTrends,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.StopKeywords,
+		arg.MinCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -357,8 +694,128 @@ func (q *Queries) KeywordTrends(ctx context.Context) ([]KeywordTrendsRow, error)
 	return items, nil
 }
 
+const keywordYearlyTrends = `-- name: KeywordYearlyTrends :many
+WITH filtered AS (
+    SELECT
+        m.movie_id,
+        EXTRACT(YEAR FROM m.release_date)::int AS year
+    FROM movie m
+    WHERE
+        m.release_date IS NOT NULL
+        AND m.vote_average > 0
+        AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+        AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+        AND ($1::int IS NOT NULL OR $2::int IS NOT NULL OR EXTRACT(YEAR FROM m.release_date) <= 2016)
+        AND ($3::text IS NULL OR EXISTS (
+            SELECT 1
+            FROM movie_genres f_mg
+                JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+            WHERE
+                f_mg.movie_id = m.movie_id
+                AND lower(f_g.genre_name) = lower($3::text)
+        ))
+        AND ($4::text IS NULL OR EXISTS (
+            SELECT 1
+            FROM production_country f_pc
+                JOIN country f_c ON f_pc.country_id = f_c.country_id
+            WHERE
+                f_pc.movie_id = m.movie_id
+                AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+        ))
+        AND ($5::text IS NULL OR EXISTS (
+            SELECT 1
+            FROM movie_languages f_ml
+                JOIN language f_l ON f_ml.language_id = f_l.language_id
+            WHERE
+                f_ml.movie_id = m.movie_id
+                AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+        ))
+        AND ($6::int IS NULL OR m.vote_count >= $6::int)
+),
+top_keywords AS (
+    SELECT
+        k.keyword_id,
+        k.keyword_name
+    FROM keyword k
+        JOIN movie_keywords mk ON k.keyword_id = mk.keyword_id
+        JOIN filtered f ON mk.movie_id = f.movie_id
+    WHERE
+        $7::text[] IS NULL
+        OR lower(k.keyword_name) <> ALL($7::text[])
+    GROUP BY
+        k.keyword_id,
+        k.keyword_name
+    HAVING
+        COUNT(f.movie_id) >= COALESCE($8::int, 10)
+    ORDER BY COUNT(f.movie_id) DESC, k.keyword_name
+    LIMIT COALESCE($9::int, 10)
+)
+SELECT
+    f.year,
+    tk.keyword_name,
+    COUNT(f.movie_id) AS movies_count
+FROM top_keywords tk
+    JOIN movie_keywords mk ON tk.keyword_id = mk.keyword_id
+    JOIN filtered f ON mk.movie_id = f.movie_id
+GROUP BY
+    f.year,
+    tk.keyword_id,
+    tk.keyword_name
+ORDER BY f.year, movies_count DESC
+`
+
+type KeywordYearlyTrendsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	StopKeywords []string    `json:"stop_keywords"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
+type KeywordYearlyTrendsRow struct {
+	Year        int32       `json:"year"`
+	KeywordName pgtype.Text `json:"keyword_name"`
+	MoviesCount int64       `json:"movies_count"`
+}
+
+// Movies per year for each of the most frequent keywords (movies without a release date are excluded)
+func (q *Queries) KeywordYearlyTrends(ctx context.Context, arg KeywordYearlyTrendsParams) ([]KeywordYearlyTrendsRow, error) {
+	rows, err := q.db.Query(ctx, keywordYearlyTrends,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.StopKeywords,
+		arg.MinCount,
+		arg.TopN,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []KeywordYearlyTrendsRow
+	for rows.Next() {
+		var i KeywordYearlyTrendsRow
+		if err := rows.Scan(&i.Year, &i.KeywordName, &i.MoviesCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const languagePopularity = `-- name: LanguagePopularity :many
 SELECT
+    lr.language_role,
     l.language_name,
     COUNT(m.movie_id) as movies_count,
     ROUND(AVG(m.vote_average), 2) as avg_rating,
@@ -367,17 +824,60 @@ SELECT
 FROM language l
     JOIN movie_languages ml ON l.language_id = ml.language_id
     JOIN movie m ON ml.movie_id = m.movie_id
+    LEFT JOIN language_role lr ON ml.language_role_id = lr.role_id
 WHERE
     m.vote_average > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
+    lr.role_id,
+    lr.language_role,
     l.language_id,
     l.language_name
 HAVING
-    COUNT(m.movie_id) >= 5
-ORDER BY movies_count DESC
+    COUNT(m.movie_id) >= COALESCE($7::int, 5)
+ORDER BY movies_count DESC, lr.role_id
+LIMIT $8::int
 `
 
+type LanguagePopularityParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type LanguagePopularityRow struct {
+	LanguageRole  pgtype.Text `json:"language_role"`
 	LanguageName  pgtype.Text `json:"language_name"`
 	MoviesCount   int64       `json:"movies_count"`
 	AvgRating     float64     `json:"avg_rating"`
@@ -385,9 +885,18 @@ type LanguagePopularityRow struct {
 	AvgPopularity float64     `json:"avg_popularity"`
 }
 
-// Analysis of original movie languages
-func (q *Queries) LanguagePopularity(ctx context.Context) ([]LanguagePopularityRow, error) {
-	rows, err := q.db.Query(ctx, languagePopularity)
+// Analysis of movie languages by role (original or spoken)
+func (q *Queries) LanguagePopularity(ctx context.Context, arg LanguagePopularityParams) ([]LanguagePopularityRow, error) {
+	rows, err := q.db.Query(ctx, languagePopularity,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.MinCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -396,6 +905,7 @@ func (q *Queries) LanguagePopularity(ctx context.Context) ([]LanguagePopularityR
 	for rows.Next() {
 		var i LanguagePopularityRow
 		if err := rows.Scan(
+			&i.LanguageRole,
 			&i.LanguageName,
 			&i.MoviesCount,
 			&i.AvgRating,
@@ -414,25 +924,62 @@ func (q *Queries) LanguagePopularity(ctx context.Context) ([]LanguagePopularityR
 
 const listTopProfitableMovies = `-- name: ListTopProfitableMovies :many
 SELECT
-    title,
-    budget,
-    revenue,
-    (revenue - budget) as profit,
+    m.title,
+    m.budget,
+    m.revenue,
+    (m.revenue - m.budget) as profit,
     ROUND(
         (
-            revenue::numeric / NULLIF(budget, 0) - 1
+            m.revenue::numeric / NULLIF(m.budget, 0) - 1
         ) * 100,
         2
     ) as roi_percent,
-    vote_average
-FROM movie
+    m.vote_average
+FROM movie m
 WHERE
-    budget > 0
-    AND revenue > 0
+    m.budget > 0
+    AND m.revenue > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 ORDER BY profit DESC
-LIMIT 400
+LIMIT COALESCE($7::int, 400)
 `
 
+type ListTopProfitableMoviesParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type ListTopProfitableMoviesRow struct {
 	Title       pgtype.Text    `json:"title"`
 	Budget      pgtype.Int4    `json:"budget"`
@@ -443,8 +990,16 @@ type ListTopProfitableMoviesRow struct {
 }
 
 // Shows movies with highest revenue and profitability
-func (q *Queries) ListTopProfitableMovies(ctx context.Context) ([]ListTopProfitableMoviesRow, error) {
-	rows, err := q.db.Query(ctx, listTopProfitableMovies)
+func (q *Queries) ListTopProfitableMovies(ctx context.Context, arg ListTopProfitableMoviesParams) ([]ListTopProfitableMoviesRow, error) {
+	rows, err := q.db.Query(ctx, listTopProfitableMovies,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -470,32 +1025,244 @@ func (q *Queries) ListTopProfitableMovies(ctx context.Context) ([]ListTopProfita
 	return items, nil
 }
 
+const monthlyReleases = `-- name: MonthlyReleases :many
+SELECT
+    EXTRACT(YEAR FROM m.release_date)::int AS year,
+    EXTRACT(MONTH FROM m.release_date)::int AS month,
+    COUNT(*) AS movies_count
+FROM movie m
+WHERE
+    m.release_date IS NOT NULL
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
+GROUP BY
+    EXTRACT(YEAR FROM m.release_date),
+    EXTRACT(MONTH FROM m.release_date)
+ORDER BY year, month
+`
+
+type MonthlyReleasesParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+}
+
+type MonthlyReleasesRow struct {
+	Year        int32 `json:"year"`
+	Month       int32 `json:"month"`
+	MoviesCount int64 `json:"movies_count"`
+}
+
+// Number of movies released per month of each year (movies without a release date are excluded)
+func (q *Queries) MonthlyReleases(ctx context.Context, arg MonthlyReleasesParams) ([]MonthlyReleasesRow, error) {
+	rows, err := q.db.Query(ctx, monthlyReleases,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MonthlyReleasesRow
+	for rows.Next() {
+		var i MonthlyReleasesRow
+		if err := rows.Scan(&i.Year, &i.Month, &i.MoviesCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const movieNumericMetrics = `-- name: MovieNumericMetrics :many
+SELECT
+    m.budget,
+    m.revenue,
+    m.runtime,
+    m.vote_average,
+    m.vote_count,
+    m.popularity
+FROM movie m
+WHERE
+    ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
+`
+
+type MovieNumericMetricsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+}
+
+type MovieNumericMetricsRow struct {
+	Budget      pgtype.Int4    `json:"budget"`
+	Revenue     pgtype.Int8    `json:"revenue"`
+	Runtime     pgtype.Int4    `json:"runtime"`
+	VoteAverage pgtype.Numeric `json:"vote_average"`
+	VoteCount   pgtype.Int4    `json:"vote_count"`
+	Popularity  pgtype.Numeric `json:"popularity"`
+}
+
+// Raw numeric columns of every movie for distribution charts
+func (q *Queries) MovieNumericMetrics(ctx context.Context, arg MovieNumericMetricsParams) ([]MovieNumericMetricsRow, error) {
+	rows, err := q.db.Query(ctx, movieNumericMetrics,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []MovieNumericMetricsRow
+	for rows.Next() {
+		var i MovieNumericMetricsRow
+		if err := rows.Scan(
+			&i.Budget,
+			&i.Revenue,
+			&i.Runtime,
+			&i.VoteAverage,
+			&i.VoteCount,
+			&i.Popularity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const runtimeSuccessSegments = `-- name: RuntimeSuccessSegments :many
 SELECT
     CASE
-        WHEN runtime < 90 THEN 'Short (<90 min)'
-        WHEN runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
-        WHEN runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
+        WHEN m.runtime < 90 THEN 'Short (<90 min)'
+        WHEN m.runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
+        WHEN m.runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
         ELSE 'Very long (>150 min)'
     END as duration_category,
     COUNT(*) as movies_count,
-    ROUND(AVG(revenue), 0) as avg_revenue,
-    ROUND(AVG(vote_average), 2) as avg_rating,
-    ROUND(AVG(popularity), 2) as avg_popularity
-FROM movie
+    ROUND(AVG(m.revenue), 0) as avg_revenue,
+    ROUND(AVG(m.vote_average), 2) as avg_rating,
+    ROUND(AVG(m.popularity), 2) as avg_popularity
+FROM movie m
 WHERE
-    runtime IS NOT NULL
-    AND runtime > 0
+    m.runtime IS NOT NULL
+    AND m.runtime > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
     CASE
-        WHEN runtime < 90 THEN 'Short (<90 min)'
-        WHEN runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
-        WHEN runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
+        WHEN m.runtime < 90 THEN 'Short (<90 min)'
+        WHEN m.runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
+        WHEN m.runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
         ELSE 'Very long (>150 min)'
     END
 ORDER BY avg_revenue DESC
 `
 
+type RuntimeSuccessSegmentsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+}
+
 type RuntimeSuccessSegmentsRow struct {
 	DurationCategory string  `json:"duration_category"`
 	MoviesCount      int64   `json:"movies_count"`
@@ -505,8 +1272,15 @@ type RuntimeSuccessSegmentsRow struct {
 }
 
 // TOPIC 7: MOVIE DURATION AND COMMERCIAL SUCCESS
-func (q *Queries) RuntimeSuccessSegments(ctx context.Context) ([]RuntimeSuccessSegmentsRow, error) {
-	rows, err := q.db.Query(ctx, runtimeSuccessSegments)
+func (q *Queries) RuntimeSuccessSegments(ctx context.Context, arg RuntimeSuccessSegmentsParams) ([]RuntimeSuccessSegmentsRow, error) {
+	rows, err := q.db.Query(ctx, runtimeSuccessSegments,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -543,15 +1317,53 @@ FROM production_company pc
     JOIN movie m ON mcom.movie_id = m.movie_id
 WHERE
     m.revenue > 0
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
     pc.company_id,
     pc.company_name
 HAVING
-    COUNT(m.movie_id) >= 3
+    COUNT(m.movie_id) >= COALESCE($7::int, 3)
 ORDER BY total_revenue DESC
-LIMIT 15
+LIMIT COALESCE($8::int, 15)
 `
 
+type StudioPerformanceParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+	MinCount     pgtype.Int4 `json:"min_count"`
+	TopN         pgtype.Int4 `json:"top_n"`
+}
+
 type StudioPerformanceRow struct {
 	CompanyName  pgtype.Text `json:"company_name"`
 	MoviesCount  int64       `json:"movies_count"`
@@ -561,8 +1373,17 @@ type StudioPerformanceRow struct {
 }
 
 // Top studios by number of movies and average profit
-func (q *Queries) StudioPerformance(ctx context.Context) ([]StudioPerformanceRow, error) {
-	rows, err := q.db.Query(ctx, studioPerformance)
+func (q *Queries) StudioPerformance(ctx context.Context, arg StudioPerformanceParams) ([]StudioPerformanceRow, error) {
+	rows, err := q.db.Query(ctx, studioPerformance,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+		arg.MinCount,
+		arg.TopN,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -589,21 +1410,57 @@ func (q *Queries) StudioPerformance(ctx context.Context) ([]StudioPerformanceRow
 
 const yearlyTrends = `-- name: YearlyTrends :many
 SELECT
-    EXTRACT(YEAR FROM release_date)::int AS year,
+    EXTRACT(YEAR FROM m.release_date)::int AS year,
     COUNT(*) AS movies_count,
-    ROUND(AVG(budget), 0) AS avg_budget,
-    ROUND(AVG(revenue), 0) AS avg_revenue,
-    ROUND(AVG(vote_average), 2) AS avg_rating,
-    ROUND(AVG(runtime), 0) AS avg_runtime
-FROM movie
+    ROUND(AVG(m.budget), 0) AS avg_budget,
+    ROUND(AVG(m.revenue), 0) AS avg_revenue,
+    ROUND(AVG(m.vote_average), 2) AS avg_rating,
+    ROUND(AVG(m.runtime), 0) AS avg_runtime
+FROM movie m
 WHERE
-    release_date IS NOT NULL
-    AND EXTRACT(YEAR FROM release_date) < 2017
+    m.release_date IS NOT NULL
+    AND ($1::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= $1::int)
+    AND ($2::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= $2::int)
+    AND ($1::int IS NOT NULL OR $2::int IS NOT NULL OR EXTRACT(YEAR FROM m.release_date) <= 2016)
+    AND ($3::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower($3::text)
+    ))
+    AND ($4::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower($4::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND ($5::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower($5::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND ($6::int IS NULL OR m.vote_count >= $6::int)
 GROUP BY
-    EXTRACT(YEAR FROM release_date)
+    EXTRACT(YEAR FROM m.release_date)
 ORDER BY year
 `
 
+type YearlyTrendsParams struct {
+	YearFrom     pgtype.Int4 `json:"year_from"`
+	YearTo       pgtype.Int4 `json:"year_to"`
+	Genre        pgtype.Text `json:"genre"`
+	Country      pgtype.Text `json:"country"`
+	Language     pgtype.Text `json:"language"`
+	MinVoteCount pgtype.Int4 `json:"min_vote_count"`
+}
+
 type YearlyTrendsRow struct {
 	Year        int32   `json:"year"`
 	MoviesCount int64   `json:"movies_count"`
@@ -614,8 +1471,15 @@ type YearlyTrendsRow struct {
 }
 
 // Number of movies and average metrics by year
-func (q *Queries) YearlyTrends(ctx context.Context) ([]YearlyTrendsRow, error) {
-	rows, err := q.db.Query(ctx, yearlyTrends)
+func (q *Queries) YearlyTrends(ctx context.Context, arg YearlyTrendsParams) ([]YearlyTrendsRow, error) {
+	rows, err := q.db.Query(ctx, yearlyTrends,
+		arg.YearFrom,
+		arg.YearTo,
+		arg.Genre,
+		arg.Country,
+		arg.Language,
+		arg.MinVoteCount,
+	)
 	if err != nil {
 		return nil, err
 	}
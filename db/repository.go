@@ -0,0 +1,16 @@
+package db
+
+import "context"
+
+// Repository is the data source behind the charts and the API: the analytics
+// queries plus QueryTable for chart specs. *Queries reads Postgres, *Memory
+// serves fixtures.
+type Repository interface {
+	Querier
+	QueryTable(ctx context.Context, sql string, args ...interface{}) (*Table, error)
+}
+
+var (
+	_ Repository = (*Queries)(nil)
+	_ Repository = (*Memory)(nil)
+)
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Table is a query result whose columns are only known at run time, as used by
+// chart specs and ad-hoc SQL. Values are decoded with pgx's default type map.
+type Table struct {
+	Columns []pgconn.FieldDescription
+	Rows    [][]any
+}
+
+// Column returns the index of the named column, or -1.
+func (t *Table) Column(name string) int {
+	for i, c := range t.Columns {
+		if c.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// ColumnNames lists the result columns in order.
+func (t *Table) ColumnNames() []string {
+	names := make([]string, len(t.Columns))
+	for i, c := range t.Columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// QueryTable runs sql and collects every row into a Table.
+func (q *Queries) QueryTable(ctx context.Context, sql string, args ...interface{}) (*Table, error) {
+	rows, err := q.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	t := &Table{Columns: append([]pgconn.FieldDescription(nil), rows.FieldDescriptions()...)}
+	for rows.Next() {
+		vals, err := rows.Values()
+		if err != nil {
+			return nil, err
+		}
+		t.Rows = append(t.Rows, vals)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// namedQuery is a sqlc query exposed by name together with its parameters.
+type namedQuery struct {
+	sql  string
+	args func(Filter) []any
+}
+
+// namedQueries exposes the sqlc queries by name so they can be run through
+// QueryTable. Keep in sync with queries/queries.sql.
+var namedQueries = map[string]namedQuery{
+	"ActorRoleCounts":         {actorRoleCounts, func(f Filter) []any { return paramArgs(f.ActorRoleCountsParams()) }},
+	"CountUndatedMovies":      {countUndatedMovies, func(f Filter) []any { return paramArgs(f.CountUndatedMoviesParams()) }},
+	"CountryProductionStats":  {countryProductionStats, func(f Filter) []any { return paramArgs(f.CountryProductionStatsParams()) }},
+	"DecadeTrends":            {decadeTrends, func(f Filter) []any { return paramArgs(f.DecadeTrendsParams()) }},
+	"DirectorPerformance":     {directorPerformance, func(f Filter) []any { return paramArgs(f.DirectorPerformanceParams()) }},
+	"GenreAverageMetrics":     {genreAverageMetrics, func(f Filter) []any { return paramArgs(f.GenreAverageMetricsParams()) }},
+	"KeywordTrends":           {keywordTrends, func(f Filter) []any { return paramArgs(f.KeywordTrendsParams()) }},
+	"KeywordYearlyTrends":     {keywordYearlyTrends, func(f Filter) []any { return paramArgs(f.KeywordYearlyTrendsParams()) }},
+	"LanguagePopularity":      {languagePopularity, func(f Filter) []any { return paramArgs(f.LanguagePopularityParams()) }},
+	"ListTopProfitableMovies": {listTopProfitableMovies, func(f Filter) []any { return paramArgs(f.ListTopProfitableMoviesParams()) }},
+	"MonthlyReleases":         {monthlyReleases, func(f Filter) []any { return paramArgs(f.MonthlyReleasesParams()) }},
+	"MovieNumericMetrics":     {movieNumericMetrics, func(f Filter) []any { return paramArgs(f.MovieNumericMetricsParams()) }},
+	"RuntimeSuccessSegments":  {runtimeSuccessSegments, func(f Filter) []any { return paramArgs(f.RuntimeSuccessSegmentsParams()) }},
+	"StudioPerformance":       {studioPerformance, func(f Filter) []any { return paramArgs(f.StudioPerformanceParams()) }},
+	"YearlyTrends":            {yearlyTrends, func(f Filter) []any { return paramArgs(f.YearlyTrendsParams()) }},
+}
+
+// NamedQuery returns the SQL of a sqlc query such as "YearlyTrends" and its
+// arguments for filter f.
+func NamedQuery(name string, f Filter) (string, []any, bool) {
+	q, ok := namedQueries[name]
+	if !ok {
+		return "", nil, false
+	}
+	return q.sql, q.args(f), true
+}
+
+// NamedQueryNames lists the queries available through NamedQuery.
+func NamedQueryNames() []string {
+	names := make([]string, 0, len(namedQueries))
+	for name := range namedQueries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
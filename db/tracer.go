@@ -0,0 +1,44 @@
+package db
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type traceStartKey struct{}
+
+// queryTracer logs every query through slog with the caller's context, so the
+// logger session and name set by the chart service show up on query lines.
+type queryTracer struct{}
+
+func (queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	slog.DebugContext(ctx, "query start", slog.String("query", queryName(data.SQL)))
+	return context.WithValue(ctx, traceStartKey{}, time.Now())
+}
+
+func (queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	attrs := []any{slog.String("rows", data.CommandTag.String())}
+	if start, ok := ctx.Value(traceStartKey{}).(time.Time); ok {
+		attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+	}
+	if data.Err != nil {
+		slog.ErrorContext(ctx, "query failed", append(attrs, slog.String("error", data.Err.Error()))...)
+		return
+	}
+	slog.DebugContext(ctx, "query done", attrs...)
+}
+
+// queryName extracts the sqlc query name from the "-- name: X :kind" header,
+// falling back to the first line of the statement.
+func queryName(sql string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(sql), "\n")
+	if rest, ok := strings.CutPrefix(line, "-- name: "); ok {
+		name, _, _ := strings.Cut(rest, " ")
+		return name
+	}
+	return line
+}
@@ -9,7 +9,10 @@ require (
 
 require github.com/jackc/puddle/v2 v2.2.2 // indirect
 
-require golang.org/x/sync v0.13.0 // indirect
+require (
+	golang.org/x/sync v0.13.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/jackc/pgpassfile v1.0.0 // indirect
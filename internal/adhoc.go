@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"encoding/csv"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dv/db"
+)
+
+// ColumnKind is the broad type of a result column, inferred from the pgx field
+// description.
+type ColumnKind string
+
+const (
+	KindNumber ColumnKind = "number"
+	KindTime   ColumnKind = "time"
+	KindText   ColumnKind = "text"
+)
+
+// KindOf classifies a result column by its type OID.
+func KindOf(fd pgconn.FieldDescription) ColumnKind {
+	switch fd.DataTypeOID {
+	case pgtype.Int2OID, pgtype.Int4OID, pgtype.Int8OID, pgtype.Float4OID, pgtype.Float8OID, pgtype.NumericOID:
+		return KindNumber
+	case pgtype.DateOID, pgtype.TimestampOID, pgtype.TimestamptzOID:
+		return KindTime
+	}
+	return KindText
+}
+
+// InferMapping fills the x and y columns a spec leaves empty from the result's
+// column types: scatter plots take the first two numeric columns, other charts
+// the first non-numeric column as categories and the numeric ones as values.
+func InferMapping(s *Spec, t *db.Table) {
+	var numeric, other []string
+	for _, fd := range t.Columns {
+		if KindOf(fd) == KindNumber {
+			numeric = append(numeric, fd.Name)
+		} else {
+			other = append(other, fd.Name)
+		}
+	}
+	used := func(name string) bool {
+		return name == s.X || name == s.Series || name == s.Color || slices.Contains(s.Y, name)
+	}
+	free := func(cols []string) []string {
+		var out []string
+		for _, c := range cols {
+			if !used(c) {
+				out = append(out, c)
+			}
+		}
+		return out
+	}
+
+	if s.X == "" {
+		candidates := free(other)
+		if s.Type == SpecScatter || len(candidates) == 0 {
+			candidates = free(numeric)
+		}
+		if len(candidates) > 0 {
+			s.X = candidates[0]
+		}
+	}
+	if len(s.Y) == 0 {
+		values := free(numeric)
+		switch {
+		case len(values) == 0:
+		case s.Type == SpecScatter || s.Type == SpecPie || s.Series != "":
+			s.Y = values[:1]
+		default:
+			s.Y = values
+		}
+	}
+}
+
+// WriteCSV writes the query result with a header row to filename in the charts
+// directory.
+func (c *Charts) WriteCSV(filename string, t *db.Table) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(c.dir + filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := csv.NewWriter(f)
+	if err := w.Write(t.ColumnNames()); err != nil {
+		return err
+	}
+	record := make([]string, len(t.Columns))
+	for _, row := range t.Rows {
+		for i, v := range row {
+			record[i] = csvCell(v)
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// csvCell writes numbers in full precision without grouping so the file reads
+// back into any tool regardless of locale.
+func csvCell(v any) string {
+	switch t := v.(type) {
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case time.Time:
+		return t.Format(time.RFC3339)
+	}
+	return cellString(v)
+}
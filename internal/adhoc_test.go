@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"math/big"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dv/db"
+)
+
+func TestKindOf(t *testing.T) {
+	for oid, want := range map[uint32]ColumnKind{
+		pgtype.Int2OID:        KindNumber,
+		pgtype.Int4OID:        KindNumber,
+		pgtype.Int8OID:        KindNumber,
+		pgtype.Float4OID:      KindNumber,
+		pgtype.Float8OID:      KindNumber,
+		pgtype.NumericOID:     KindNumber,
+		pgtype.DateOID:        KindTime,
+		pgtype.TimestampOID:   KindTime,
+		pgtype.TimestamptzOID: KindTime,
+		pgtype.TextOID:        KindText,
+		pgtype.VarcharOID:     KindText,
+		pgtype.BoolOID:        KindText,
+	} {
+		if got := KindOf(pgconn.FieldDescription{DataTypeOID: oid}); got != want {
+			t.Errorf("KindOf(%d) = %s, want %s", oid, got, want)
+		}
+	}
+}
+
+// columns builds a result of the named columns, of the kinds given by the
+// OIDs.
+func resultOf(cols ...any) *db.Table {
+	t := &db.Table{}
+	for i := 0; i < len(cols); i += 2 {
+		t.Columns = append(t.Columns, pgconn.FieldDescription{Name: cols[i].(string), DataTypeOID: cols[i+1].(uint32)})
+	}
+	return t
+}
+
+func TestInferMapping(t *testing.T) {
+	result := resultOf("genre", uint32(pgtype.TextOID), "movies", uint32(pgtype.Int8OID),
+		"rating", uint32(pgtype.NumericOID), "released", uint32(pgtype.DateOID))
+	for _, tc := range []struct {
+		name string
+		spec Spec
+		x    string
+		y    []string
+	}{
+		{"bar", Spec{Type: SpecBar}, "genre", []string{"movies", "rating"}},
+		{"pie", Spec{Type: SpecPie}, "genre", []string{"movies"}},
+		{"scatter", Spec{Type: SpecScatter}, "movies", []string{"rating"}},
+		{"series", Spec{Type: SpecLine, Series: "genre"}, "released", []string{"movies"}},
+		{"given x", Spec{Type: SpecBar, X: "released"}, "released", []string{"movies", "rating"}},
+		{"given y", Spec{Type: SpecBar, Y: []string{"rating"}}, "genre", []string{"rating"}},
+		{"color", Spec{Type: SpecScatter, Color: "movies"}, "rating", nil}, // no numeric column left for y
+	} {
+		s := tc.spec
+		InferMapping(&s, result)
+		if s.X != tc.x || !slices.Equal(s.Y, tc.y) {
+			t.Errorf("%s: x=%q y=%v, want x=%q y=%v", tc.name, s.X, s.Y, tc.x, tc.y)
+		}
+	}
+
+	// only numbers: the categories fall back to the first numeric column
+	s := Spec{Type: SpecBar}
+	InferMapping(&s, resultOf("year", uint32(pgtype.Int4OID), "movies", uint32(pgtype.Int8OID)))
+	if s.X != "year" || !slices.Equal(s.Y, []string{"movies"}) {
+		t.Errorf("numeric result: x=%q y=%v, want year and movies", s.X, s.Y)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	result := resultOf("genre", uint32(pgtype.TextOID), "rating", uint32(pgtype.Float8OID),
+		"revenue", uint32(pgtype.NumericOID), "released", uint32(pgtype.TimestamptzOID))
+	result.Rows = [][]any{
+		{"Drama, Romance", 7.25, pgtype.Numeric{Int: big.NewInt(1234567), Exp: -2, Valid: true}, time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)},
+		{`say "hi"`, 1e21, pgtype.Numeric{}, nil},
+	}
+	dir := t.TempDir() + "/"
+	c := NewCharts(nil, dir)
+	if err := c.WriteCSV("result.csv", result); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(dir + "result.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "genre,rating,revenue,released\n" +
+		"\"Drama, Romance\",7.25,12345.67,2001-02-03T04:05:06Z\n" +
+		"\"say \"\"hi\"\"\",1000000000000000000000,,\n"
+	if string(b) != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", b, want)
+	}
+}
@@ -0,0 +1,212 @@
+// Package api serves the analytics queries as a versioned JSON REST API, so
+// the frontend and notebooks read the same numbers the charts show.
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"time"
+
+	"dv/db"
+	"dv/pkg/logger"
+)
+
+const (
+	Version = "v1"
+	Prefix  = "/api/" + Version + "/"
+)
+
+// API is an http.Handler for everything under /api/.
+type API struct {
+	queries   db.Repository
+	timeout   time.Duration
+	mux       *http.ServeMux
+	endpoints []endpoint
+}
+
+type Option func(*API)
+
+// WithTimeout bounds each request's query; 0 leaves it to the client.
+func WithTimeout(d time.Duration) Option {
+	return func(a *API) {
+		a.timeout = d
+	}
+}
+
+func New(q db.Repository, opts ...Option) *API {
+	a := &API{
+		queries:   q,
+		mux:       http.NewServeMux(),
+		endpoints: endpoints(),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	for _, e := range a.endpoints {
+		a.mux.HandleFunc(Prefix+e.path, a.get(a.handleQuery(e)))
+	}
+	a.mux.HandleFunc(Prefix+"openapi.json", a.get(a.handleOpenAPI))
+	a.mux.HandleFunc("/api/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "not_found", "no endpoint at "+r.URL.Path)
+	})
+	return a
+}
+
+func (a *API) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	a.mux.ServeHTTP(w, r)
+}
+
+// listResponse wraps the rows of every query endpoint.
+type listResponse struct {
+	Query string   `json:"query"`
+	Count int      `json:"count"`
+	Rows  []object `json:"rows"`
+}
+
+// errorResponse is returned with every non-2xx status.
+type errorResponse struct {
+	Error apiError `json:"error"`
+}
+
+type apiError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// get rejects everything but GET and HEAD with a JSON 405.
+func (a *API) get(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			writeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method+" is not supported")
+			return
+		}
+		h(w, r)
+	}
+}
+
+func (a *API) handleQuery(e endpoint) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := logger.WithName(r.Context(), "api."+e.query)
+		if a.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, a.timeout)
+			defer cancel()
+		}
+		filter, err := parseFilter(r.URL.Query(), e.params)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+			return
+		}
+		rows, err := e.fetch(ctx, a.queries, filter)
+		switch {
+		case err == nil:
+		case errors.Is(err, context.DeadlineExceeded):
+			writeError(w, http.StatusGatewayTimeout, "timeout", e.query+" did not finish in time")
+			return
+		case r.Context().Err() != nil:
+			// the client went away; nobody reads the response
+			return
+		default:
+			slog.ErrorContext(ctx, "api query failed", slog.String("error", err.Error()))
+			writeError(w, http.StatusInternalServerError, "query_failed", e.query+" failed")
+			return
+		}
+		writeJSON(w, http.StatusOK, listResponse{Query: e.query, Count: len(rows), Rows: rows})
+	}
+}
+
+func (a *API) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.openAPI())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		slog.Error("failed to encode API response", slog.String("error", err.Error()))
+		status, buf = http.StatusInternalServerError, bytes.Buffer{}
+		json.NewEncoder(&buf).Encode(errorResponse{apiError{status, "encoding_failed", "response could not be encoded"}})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+}
+
+func writeError(w http.ResponseWriter, status int, code, msg string) {
+	writeJSON(w, status, errorResponse{apiError{Status: status, Code: code, Message: msg}})
+}
+
+// endpoint exposes one sqlc query at Prefix+path.
+type endpoint struct {
+	path    string
+	query   string
+	summary string
+	params  []string // accepted query parameters, the json names of the sqlc Params fields
+	row     reflect.Type
+	fetch   func(ctx context.Context, q db.Repository, f db.Filter) ([]object, error)
+}
+
+// list adapts a generated :many query method; params builds its arguments from
+// the request's filter.
+func list[P, T any](path, query, summary string, fn func(db.Repository, context.Context, P) ([]T, error), params func(db.Filter) P) endpoint {
+	var names []string
+	for _, f := range reflect.VisibleFields(reflect.TypeFor[P]()) {
+		if name, ok := jsonName(f); ok {
+			names = append(names, name)
+		}
+	}
+	return endpoint{
+		path:    path,
+		query:   query,
+		summary: summary,
+		params:  names,
+		row:     reflect.TypeFor[T](),
+		fetch: func(ctx context.Context, q db.Repository, f db.Filter) ([]object, error) {
+			rows, err := fn(q, ctx, params(f))
+			if err != nil {
+				return nil, err
+			}
+			out := make([]object, len(rows))
+			for i := range rows {
+				out[i] = toObject(reflect.ValueOf(rows[i]))
+			}
+			return out, nil
+		},
+	}
+}
+
+// undatedMoviesRow gives the :one CountUndatedMovies query a row like the others.
+type undatedMoviesRow struct {
+	MoviesCount int64 `json:"movies_count"`
+}
+
+func endpoints() []endpoint {
+	return []endpoint{
+		list("actor-role-counts", "ActorRoleCounts", "Actors with the most roles and the average rating of their movies", db.Repository.ActorRoleCounts, db.Filter.ActorRoleCountsParams),
+		list("country-production-stats", "CountryProductionStats", "Geography of film production and average metrics", db.Repository.CountryProductionStats, db.Filter.CountryProductionStatsParams),
+		list("decade-trends", "DecadeTrends", "Number of movies and average metrics by decade", db.Repository.DecadeTrends, db.Filter.DecadeTrendsParams),
+		list("director-performance", "DirectorPerformance", "Top directors by average metrics of their movies", db.Repository.DirectorPerformance, db.Filter.DirectorPerformanceParams),
+		list("genre-average-metrics", "GenreAverageMetrics", "Average metrics by genre", db.Repository.GenreAverageMetrics, db.Filter.GenreAverageMetricsParams),
+		list("keyword-trends", "KeywordTrends", "Most frequent keywords and the average rating of their movies", db.Repository.KeywordTrends, db.Filter.KeywordTrendsParams),
+		list("keyword-yearly-trends", "KeywordYearlyTrends", "Movies per year for each of the most frequent keywords", db.Repository.KeywordYearlyTrends, db.Filter.KeywordYearlyTrendsParams),
+		list("language-popularity", "LanguagePopularity", "Movie languages by role (original or spoken), number of movies and average metrics", db.Repository.LanguagePopularity, db.Filter.LanguagePopularityParams),
+		list("monthly-releases", "MonthlyReleases", "Movies released per month of each year, undated movies excluded", db.Repository.MonthlyReleases, db.Filter.MonthlyReleasesParams),
+		list("movie-numeric-metrics", "MovieNumericMetrics", "Raw numeric columns of every movie", db.Repository.MovieNumericMetrics, db.Filter.MovieNumericMetricsParams),
+		list("runtime-success-segments", "RuntimeSuccessSegments", "Commercial success by runtime segment", db.Repository.RuntimeSuccessSegments, db.Filter.RuntimeSuccessSegmentsParams),
+		list("studio-performance", "StudioPerformance", "Top studios by number of movies and average profit", db.Repository.StudioPerformance, db.Filter.StudioPerformanceParams),
+		list("top-profitable-movies", "ListTopProfitableMovies", "Movies with the highest revenue and profitability", db.Repository.ListTopProfitableMovies, db.Filter.ListTopProfitableMoviesParams),
+		list("undated-movies", "CountUndatedMovies", "Number of movies without a release date", func(q db.Repository, ctx context.Context, arg db.CountUndatedMoviesParams) ([]undatedMoviesRow, error) {
+			n, err := q.CountUndatedMovies(ctx, arg)
+			return []undatedMoviesRow{{MoviesCount: n}}, err
+		}, db.Filter.CountUndatedMoviesParams),
+		list("yearly-trends", "YearlyTrends", "Number of movies and average metrics by year", db.Repository.YearlyTrends, db.Filter.YearlyTrendsParams),
+	}
+}
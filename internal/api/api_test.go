@@ -0,0 +1,156 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dv/db"
+)
+
+// brokenRepo fails GenreAverageMetrics with err, after delay or the end of
+// the request's context.
+type brokenRepo struct {
+	*db.Memory
+	err   error
+	delay time.Duration
+}
+
+func (r brokenRepo) GenreAverageMetrics(ctx context.Context, _ db.GenreAverageMetricsParams) ([]db.GenreAverageMetricsRow, error) {
+	select {
+	case <-time.After(r.delay):
+		return nil, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	fixtures, err := db.LoadFixtures("../../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok := New(fixtures)
+	failing := New(brokenRepo{Memory: fixtures, err: errors.New("password authentication failed")})
+	slow := New(brokenRepo{Memory: fixtures, delay: time.Hour}, WithTimeout(10*time.Millisecond))
+	for _, tc := range []struct {
+		api    *API
+		method string
+		path   string
+		status int
+		code   string // error code, "" for success
+	}{
+		{ok, http.MethodGet, "genre-average-metrics", http.StatusOK, ""},
+		{ok, http.MethodHead, "genre-average-metrics", http.StatusOK, ""},
+		{ok, http.MethodGet, "genre-average-metrics?year_from=1990&genre=drama", http.StatusOK, ""},
+		{ok, http.MethodGet, "undated-movies", http.StatusOK, ""},
+		{ok, http.MethodGet, "openapi.json", http.StatusOK, ""},
+		{ok, http.MethodPost, "genre-average-metrics", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{ok, http.MethodGet, "no-such-query", http.StatusNotFound, "not_found"},
+		{ok, http.MethodGet, "genre-average-metrics?year_from=1990s", http.StatusBadRequest, "invalid_parameter"},
+		{ok, http.MethodGet, "genre-average-metrics?stop_keywords=sequel", http.StatusBadRequest, "invalid_parameter"},
+		{ok, http.MethodGet, "genre-average-metrics?year_from=2010&year_to=2000", http.StatusBadRequest, "invalid_parameter"},
+		{failing, http.MethodGet, "genre-average-metrics", http.StatusInternalServerError, "query_failed"},
+		{slow, http.MethodGet, "genre-average-metrics", http.StatusGatewayTimeout, "timeout"},
+	} {
+		name := tc.method + " " + tc.path
+		rec := httptest.NewRecorder()
+		tc.api.ServeHTTP(rec, httptest.NewRequest(tc.method, Prefix+tc.path, nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", name, rec.Code, tc.status)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("%s: Content-Type %q, want application/json", name, ct)
+		}
+		if tc.code == "" {
+			continue
+		}
+		var res errorResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+			t.Errorf("%s: error body %q is not JSON: %v", name, rec.Body, err)
+			continue
+		}
+		if res.Error.Status != tc.status || res.Error.Code != tc.code || res.Error.Message == "" {
+			t.Errorf("%s: error %+v, want status %d and code %s with a message", name, res.Error, tc.status, tc.code)
+		}
+		if strings.Contains(res.Error.Message, "password") {
+			t.Errorf("%s: error %q leaks the database error", name, res.Error.Message)
+		}
+	}
+}
+
+func TestListResponse(t *testing.T) {
+	fixtures, err := db.LoadFixtures("../../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	New(fixtures).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, Prefix+"genre-average-metrics", nil))
+	var res struct {
+		Query string           `json:"query"`
+		Count int              `json:"count"`
+		Rows  []map[string]any `json:"rows"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Query != "GenreAverageMetrics" || res.Count == 0 || res.Count != len(res.Rows) {
+		t.Errorf("response %s with %d of %d rows, want GenreAverageMetrics with all its rows", res.Query, res.Count, len(res.Rows))
+	}
+	// fields are named by their json tags
+	if _, ok := res.Rows[0]["genre_name"]; !ok {
+		t.Errorf("row %v lacks genre_name", res.Rows[0])
+	}
+}
+
+func TestPlain(t *testing.T) {
+	date := time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+	for _, tc := range []struct {
+		in   any
+		want any
+	}{
+		{pgtype.Text{String: "Drama", Valid: true}, "Drama"},
+		{pgtype.Text{}, nil},
+		{pgtype.Bool{Bool: true, Valid: true}, true},
+		{pgtype.Int4{Int32: 42, Valid: true}, int32(42)},
+		{pgtype.Int8{}, nil},
+		{pgtype.Float8{Float64: 7.5, Valid: true}, 7.5},
+		{pgtype.Float8{Float64: math.NaN(), Valid: true}, nil},
+		{pgtype.Float4{Float32: float32(math.Inf(1)), Valid: true}, nil},
+		{pgtype.Numeric{Int: big.NewInt(12345), Exp: -2, Valid: true}, 123.45},
+		{pgtype.Numeric{NaN: true, Valid: true}, nil},
+		{pgtype.Numeric{InfinityModifier: pgtype.Infinity, Valid: true}, nil},
+		{pgtype.Numeric{}, nil},
+		{pgtype.Date{Time: date, Valid: true}, "2001-02-03"},
+		{pgtype.Date{InfinityModifier: pgtype.Infinity, Valid: true}, nil},
+		{pgtype.Timestamptz{Time: date, Valid: true}, "2001-02-03T04:05:06Z"},
+		{math.Inf(-1), nil},
+		{float32(0.5), 0.5},
+		{int64(7), int64(7)},
+		{"text", "text"},
+	} {
+		if got := plain(tc.in); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("plain(%#v) = %#v, want %#v", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestObjectOrder(t *testing.T) {
+	b, err := json.Marshal(object{{"z", 1}, {"a", nil}, {"m", "x"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"z":1,"a":null,"m":"x"}`; string(b) != want {
+		t.Errorf("object = %s, want %s", b, want)
+	}
+}
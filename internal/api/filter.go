@@ -0,0 +1,80 @@
+package api
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"dv/db"
+)
+
+// filterParams documents the query parameters mapped onto db.Filter; each
+// endpoint accepts those its sqlc query declares.
+var filterParams = map[string]string{
+	"year_from":      "First release year, inclusive",
+	"year_to":        "Last release year, inclusive",
+	"genre":          "Only movies of this genre (case-insensitive name)",
+	"country":        "Only movies produced in this country (name or ISO code)",
+	"language":       "Only movies in this language (name or code)",
+	"min_vote_count": "Skip movies with fewer votes",
+	"min_count":      "Skip groups with fewer movies than this",
+	"top_n":          "Maximum number of rows",
+	"stop_keywords":  "Comma-separated keywords left out of the keyword rankings (case-insensitive); defaults to production tags such as duringcreditsstinger, empty keeps all",
+}
+
+// parseFilter reads the filter from the query string; allowed lists the
+// parameters the endpoint supports. Keyword endpoints leave out
+// db.DefaultStopKeywords unless stop_keywords is given.
+func parseFilter(values url.Values, allowed []string) (db.Filter, error) {
+	var f db.Filter
+	if slices.Contains(allowed, "stop_keywords") {
+		f.StopKeywords = db.DefaultStopKeywords
+	}
+	for name := range values {
+		if !slices.Contains(allowed, name) {
+			if len(allowed) == 0 {
+				return f, fmt.Errorf("unknown parameter %q (the endpoint takes none)", name)
+			}
+			return f, fmt.Errorf("unknown parameter %q (want %s)", name, strings.Join(allowed, ", "))
+		}
+		value := values.Get(name)
+		var err error
+		switch name {
+		case "year_from":
+			f.YearFrom, err = strconv.Atoi(value)
+		case "year_to":
+			f.YearTo, err = strconv.Atoi(value)
+		case "genre":
+			f.Genre = value
+		case "country":
+			f.Country = value
+		case "language":
+			f.Language = value
+		case "min_vote_count":
+			f.MinVoteCount, err = strconv.Atoi(value)
+		case "min_count":
+			f.MinCount, err = strconv.Atoi(value)
+		case "top_n":
+			f.TopN, err = strconv.Atoi(value)
+		case "stop_keywords":
+			f.StopKeywords = splitList(value)
+		}
+		if err != nil {
+			return f, fmt.Errorf("%s: %q is not an integer", name, value)
+		}
+	}
+	return f, f.Validate()
+}
+
+// splitList reads a comma-separated parameter, skipping empty items.
+func splitList(value string) []string {
+	var out []string
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
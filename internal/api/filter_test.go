@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/url"
+	"slices"
+	"testing"
+
+	"dv/db"
+)
+
+func TestParseFilterStopKeywords(t *testing.T) {
+	keywords := []string{"year_from", "stop_keywords"}
+	for _, tc := range []struct {
+		query   string
+		allowed []string
+		want    []string
+	}{
+		{"", keywords, db.DefaultStopKeywords},
+		{"year_from=2000", keywords, db.DefaultStopKeywords},
+		{"stop_keywords=sequel,+remake", keywords, []string{"sequel", "remake"}},
+		{"stop_keywords=", keywords, nil},              // empty keeps all
+		{"year_from=2000", []string{"year_from"}, nil}, // not a keyword query
+	} {
+		values, _ := url.ParseQuery(tc.query)
+		f, err := parseFilter(values, tc.allowed)
+		if err != nil {
+			t.Errorf("parseFilter(%q) = %v", tc.query, err)
+			continue
+		}
+		if !slices.Equal(f.StopKeywords, tc.want) {
+			t.Errorf("parseFilter(%q).StopKeywords = %q, want %q", tc.query, f.StopKeywords, tc.want)
+		}
+	}
+}
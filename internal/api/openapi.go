@@ -0,0 +1,167 @@
+package api
+
+import (
+	"reflect"
+	"strconv"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// openAPI describes the endpoints as an OpenAPI 3.0 document. Row schemas are
+// derived from the sqlc row structs, so they follow the queries automatically.
+func (a *API) openAPI() map[string]any {
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type":     "object",
+			"required": []string{"error"},
+			"properties": map[string]any{
+				"error": map[string]any{
+					"type":     "object",
+					"required": []string{"status", "code", "message"},
+					"properties": map[string]any{
+						"status":  map[string]any{"type": "integer", "description": "HTTP status code"},
+						"code":    map[string]any{"type": "string", "description": "machine-readable error code", "example": "query_failed"},
+						"message": map[string]any{"type": "string"},
+					},
+				},
+			},
+		},
+	}
+	paths := make(map[string]any, len(a.endpoints)+1)
+	for _, e := range a.endpoints {
+		schemas[e.row.Name()] = rowSchema(e.row)
+		params := make([]any, 0, len(e.params))
+		for _, name := range e.params {
+			schema := map[string]any{"type": "integer", "minimum": 0}
+			switch name {
+			case "genre", "country", "language":
+				schema = map[string]any{"type": "string"}
+			case "stop_keywords":
+				schema = map[string]any{"type": "array", "items": map[string]any{"type": "string"}}
+			}
+			param := map[string]any{
+				"name":        name,
+				"in":          "query",
+				"description": filterParams[name],
+				"schema":      schema,
+			}
+			if schema["type"] == "array" {
+				param["style"], param["explode"] = "form", false
+			}
+			params = append(params, param)
+		}
+		paths[Prefix+e.path] = map[string]any{
+			"get": map[string]any{
+				"operationId": e.query,
+				"summary":     e.summary,
+				"parameters":  params,
+				"responses": map[string]any{
+					"200": map[string]any{
+						"description": e.query + " rows",
+						"content": jsonContent(map[string]any{
+							"type":     "object",
+							"required": []string{"query", "count", "rows"},
+							"properties": map[string]any{
+								"query": map[string]any{"type": "string", "example": e.query},
+								"count": map[string]any{"type": "integer"},
+								"rows":  map[string]any{"type": "array", "items": ref("schemas", e.row.Name())},
+							},
+						}),
+					},
+					"400": ref("responses", "Error"),
+					"500": ref("responses", "Error"),
+					"504": ref("responses", "Error"),
+				},
+			},
+		}
+	}
+	paths[Prefix+"openapi.json"] = map[string]any{
+		"get": map[string]any{
+			"operationId": "OpenAPI",
+			"summary":     "This document",
+			"responses": map[string]any{
+				"200": map[string]any{"description": "OpenAPI 3.0 document", "content": jsonContent(map[string]any{"type": "object"})},
+			},
+		},
+	}
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Movie Analytics API",
+			"version":     Version,
+			"description": "Rows of the analytics queries behind the charts. NULL columns and non-finite numbers are returned as null.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas,
+			"responses": map[string]any{
+				"Error": map[string]any{
+					"description": "Error; 404 and 405 are returned in the same shape",
+					"content":     jsonContent(ref("schemas", "Error")),
+				},
+			},
+		},
+	}
+}
+
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+func ref(kind, name string) map[string]any {
+	return map[string]any{"$ref": "#/components/" + kind + "/" + name}
+}
+
+func rowSchema(t reflect.Type) map[string]any {
+	props := make(map[string]any, t.NumField())
+	var required []string
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, ok := jsonName(f)
+		if !ok {
+			continue
+		}
+		props[name] = fieldSchema(f.Type)
+		required = append(required, name)
+	}
+	return map[string]any{"type": "object", "required": required, "properties": props}
+}
+
+// fieldSchema maps a row field's Go type to its schema, mirroring plain.
+func fieldSchema(t reflect.Type) map[string]any {
+	nullable := func(typ, format string) map[string]any {
+		s := map[string]any{"type": typ, "nullable": true}
+		if format != "" {
+			s["format"] = format
+		}
+		return s
+	}
+	switch t {
+	case reflect.TypeFor[pgtype.Text]():
+		return nullable("string", "")
+	case reflect.TypeFor[pgtype.Bool]():
+		return nullable("boolean", "")
+	case reflect.TypeFor[pgtype.Int2](), reflect.TypeFor[pgtype.Int4]():
+		return nullable("integer", "int32")
+	case reflect.TypeFor[pgtype.Int8]():
+		return nullable("integer", "int64")
+	case reflect.TypeFor[pgtype.Float4](), reflect.TypeFor[pgtype.Float8](), reflect.TypeFor[pgtype.Numeric]():
+		return nullable("number", "double")
+	case reflect.TypeFor[pgtype.Date]():
+		return nullable("string", "date")
+	case reflect.TypeFor[pgtype.Timestamp](), reflect.TypeFor[pgtype.Timestamptz]():
+		return nullable("string", "date-time")
+	}
+	switch t.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int" + strconv.Itoa(max(t.Bits(), 32))}
+	case reflect.Float32, reflect.Float64:
+		// NaN and ±Inf are sent as null
+		return nullable("number", "double")
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	}
+	return map[string]any{"nullable": true}
+}
@@ -0,0 +1,135 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// object is a JSON object that keeps its fields in struct order.
+type object []field
+
+type field struct {
+	name  string
+	value any
+}
+
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// toObject converts a sqlc row struct using its json tags as field names.
+func toObject(v reflect.Value) object {
+	t := v.Type()
+	o := make(object, 0, t.NumField())
+	for i := range t.NumField() {
+		if name, ok := jsonName(t.Field(i)); ok {
+			o = append(o, field{name, plain(v.Field(i).Interface())})
+		}
+	}
+	return o
+}
+
+func jsonName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	}
+	return name, true
+}
+
+// plain turns a scanned value into what the API returns: pgtype values become
+// their Go value, or nil when NULL, and numbers that JSON cannot represent
+// (NaN, ±Inf) become nil as well.
+func plain(v any) any {
+	switch t := v.(type) {
+	case pgtype.Text:
+		if t.Valid {
+			return t.String
+		}
+	case pgtype.Bool:
+		if t.Valid {
+			return t.Bool
+		}
+	case pgtype.Int2:
+		if t.Valid {
+			return t.Int16
+		}
+	case pgtype.Int4:
+		if t.Valid {
+			return t.Int32
+		}
+	case pgtype.Int8:
+		if t.Valid {
+			return t.Int64
+		}
+	case pgtype.Float4:
+		if t.Valid {
+			return finite(float64(t.Float32))
+		}
+	case pgtype.Float8:
+		if t.Valid {
+			return finite(t.Float64)
+		}
+	case pgtype.Numeric:
+		if f, err := t.Float64Value(); err == nil && f.Valid {
+			return finite(f.Float64)
+		}
+	case pgtype.Date:
+		if t.Valid && t.InfinityModifier == pgtype.Finite {
+			return t.Time.Format(time.DateOnly)
+		}
+	case pgtype.Timestamp:
+		if t.Valid && t.InfinityModifier == pgtype.Finite {
+			return t.Time.Format(time.RFC3339)
+		}
+	case pgtype.Timestamptz:
+		if t.Valid && t.InfinityModifier == pgtype.Finite {
+			return t.Time.Format(time.RFC3339)
+		}
+	case float32:
+		return finite(float64(t))
+	case float64:
+		return finite(t)
+	default:
+		return v
+	}
+	return nil
+}
+
+func finite(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil
+	}
+	return f
+}
@@ -0,0 +1,34 @@
+// Package assets embeds vendored ECharts bundles so rendered charts can work
+// without network access.
+package assets
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+//go:generate sh ../../scripts/vendor-assets.sh
+
+// DefaultHost is where go-echarts points script tags by default.
+const DefaultHost = "https://go-echarts.github.io/go-echarts-assets/assets/"
+
+//go:embed vendor
+var vendor embed.FS
+
+// Read returns a vendored asset by its path relative to DefaultHost, e.g.
+// "echarts.min.js" or "maps/world.js".
+func Read(name string) ([]byte, error) {
+	b, err := fs.ReadFile(vendor, path.Join("vendor", name))
+	if err != nil {
+		return nil, fmt.Errorf("asset %s is not vendored (run go generate ./internal/assets): %w", name, err)
+	}
+	return b, nil
+}
+
+// Has reports whether name is vendored.
+func Has(name string) bool {
+	_, err := fs.Stat(vendor, path.Join("vendor", name))
+	return err == nil
+}
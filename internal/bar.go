@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"context"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const barFile = "bar.html"
+
+type barChart struct{}
+
+func init() { Register(barChart{}) }
+
+func (barChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "bar",
+		Description: "Average rating by genre (audience preference across genres)",
+		ChartType:   "Bar",
+		Source:      "GenreAverageMetrics",
+		Output:      barFile,
+	}
+}
+
+func (barChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.BarChartWithCount(ctx)
+}
+
+func (c *Charts) BarChart(ctx context.Context) error { _, err := c.BarChartWithCount(ctx); return err }
+func (c *Charts) BarChartWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.GenreAverageMetrics(ctx, c.filter.GenreAverageMetricsParams())
+	if err != nil {
+		return 0, err
+	}
+	genres := make([]string, 0, len(data))
+	values := make([]opts.BarData, 0, len(data))
+	for _, item := range data {
+		genres = append(genres, item.GenreName.String)
+		values = append(values, opts.BarData{Value: item.AvgRating})
+	}
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: c.printer.T("Average Rating by Genre")}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.printer.T("Genre"), Type: "category"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: c.printer.T("Avg Rating")}),
+	)
+	bar.SetXAxis(genres).AddSeries(c.printer.T("Avg Rating"), values).SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Position: "top"}),
+	)
+	return len(data), c.render(bar, barFile)
+}
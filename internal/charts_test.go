@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"dv/db"
+	"dv/internal/i18n"
+)
+
+// TestCharts renders every registered chart, enabled or not, from the
+// fixtures in both output formats.
+func TestCharts(t *testing.T) {
+	repo, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []Format{FormatHTML, FormatSVG} {
+		c := NewCharts(repo, t.TempDir()+"/", WithFormat(format), WithDashboard(false))
+		for _, info := range c.Registry().List() {
+			t.Run(string(format)+"/"+info.Name, func(t *testing.T) {
+				res := c.generate(context.Background(), info)
+				if res.Err != nil {
+					t.Fatal(res.Err)
+				}
+				if res.Rows <= 0 {
+					t.Errorf("rows = %d, want some", res.Rows)
+				}
+				fi, err := os.Stat(filepath.Join(c.dir, c.outputName(info.Output)))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if fi.Size() == 0 {
+					t.Error("output is empty")
+				}
+			})
+		}
+	}
+}
+
+func TestWithRegistry(t *testing.T) {
+	registry := DefaultRegistry().Clone()
+	if err := registry.Disable("bar"); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.MarkCritical("pie"); err != nil {
+		t.Fatal(err)
+	}
+	c := NewCharts(nil, t.TempDir()+"/", WithRegistry(registry))
+	// later changes to the caller's registry do not reach c
+	registry.Enable("bar")
+
+	if info, _ := c.Registry().Info("bar"); !info.Disabled {
+		t.Error("bar is enabled, want it disabled through WithRegistry")
+	}
+	if info, _ := c.Registry().Info("pie"); !info.Critical {
+		t.Error("pie is not critical, want it marked through WithRegistry")
+	}
+	if info, _ := DefaultRegistry().Info("bar"); info.Disabled {
+		t.Error("WithRegistry disabled bar in the default registry")
+	}
+	if info, _ := DefaultRegistry().Info("pie"); info.Critical {
+		t.Error("WithRegistry marked pie critical in the default registry")
+	}
+}
+
+// TestCatalogCoverage checks that every message the charts and pages
+// translate by literal, every chart description and every month name has a
+// Russian translation.
+func TestCatalogCoverage(t *testing.T) {
+	var msgs []string
+	literal := regexp.MustCompile(`\.T\(("(?:[^"\\]|\\.)*")|\{\{-? *t ("(?:[^"\\]|\\.)*")`)
+	files, _ := filepath.Glob("*.go")
+	pages, _ := filepath.Glob("templates/*.html")
+	for _, f := range append(files, pages...) {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range literal.FindAllSubmatch(b, -1) {
+			s, err := strconv.Unquote(string(m[1]) + string(m[2]))
+			if err != nil {
+				t.Fatalf("%s: %v", f, err)
+			}
+			msgs = append(msgs, s)
+		}
+	}
+	for _, info := range DefaultRegistry().List() {
+		msgs = append(msgs, info.Description)
+	}
+	msgs = append(msgs, monthNames...)
+
+	ru := i18n.NewPrinter(i18n.Russian)
+	for _, msg := range msgs {
+		if msg == "log10 %s" { // the same in every locale
+			continue
+		}
+		if ru.T(msg) == msg {
+			t.Errorf("no Russian translation of %q", msg)
+		}
+	}
+	if len(msgs) < 100 {
+		t.Errorf("found %d messages, want the scan to reach the sources", len(msgs))
+	}
+}
@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	dashboardFile = "dashboard.html"
+	indexFile     = "index.html"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+// pageTemplates are parsed with a placeholder "t"; writeTemplate binds it to the
+// run's message catalog.
+var pageTemplates = template.Must(template.New("").Funcs(template.FuncMap{
+	"t": fmt.Sprintf,
+}).ParseFS(templateFS, "templates/*.html"))
+
+// WithDashboard toggles writing dashboard.html and index.html after a full run.
+func WithDashboard(on bool) Option {
+	return func(c *Charts) {
+		c.dashboard = on
+	}
+}
+
+type dashboardView struct {
+	Title     string
+	Generated time.Time
+	OK        int
+	Results   []ChartResult
+	Dashboard string
+	Index     string
+	Theme     Theme
+	Lang      string
+	Filter    string
+}
+
+// writeDashboard renders a single page embedding every generated chart with its
+// description, row count and timing, plus an index linking the individual pages.
+func (c *Charts) writeDashboard(report *RunReport) error {
+	view := c.dashboardView(report)
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	for name, tpl := range map[string]string{dashboardFile: "dashboard.html", indexFile: "index.html"} {
+		if err := c.writeTemplate(name, tpl, view); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Charts) dashboardView(report *RunReport) dashboardView {
+	view := dashboardView{
+		Title:     c.printer.T("Movie Analytics"),
+		Generated: time.Now(),
+		Results:   make([]ChartResult, len(report.Results)),
+		Dashboard: dashboardFile,
+		Index:     indexFile,
+		Theme:     c.theme,
+		Lang:      string(c.printer.Locale()),
+		Filter:    c.filterLabel(),
+	}
+	for i, res := range report.Results {
+		res.Duration = res.Duration.Round(time.Millisecond)
+		res.Info.Output = c.outputName(res.Info.Output)
+		view.Results[i] = res
+		if res.Status == StatusOK {
+			view.OK++
+		}
+	}
+	return view
+}
+
+// filterLabel describes the run's filter, or returns "" when it has none.
+func (c *Charts) filterLabel() string {
+	f, p := c.filter, c.printer
+	var parts []string
+	switch {
+	case f.YearFrom > 0 && f.YearTo > 0:
+		parts = append(parts, p.T("years %d–%d", f.YearFrom, f.YearTo))
+	case f.YearFrom > 0:
+		parts = append(parts, p.T("from %d", f.YearFrom))
+	case f.YearTo > 0:
+		parts = append(parts, p.T("until %d", f.YearTo))
+	}
+	for _, s := range []struct{ format, value string }{{"genre %s", f.Genre}, {"country %s", f.Country}, {"language %s", f.Language}} {
+		if s.value != "" {
+			parts = append(parts, p.T(s.format, s.value))
+		}
+	}
+	for _, n := range []struct {
+		format string
+		value  int
+	}{{"at least %d votes", f.MinVoteCount}, {"at least %d movies", f.MinCount}, {"top %d", f.TopN}} {
+		if n.value > 0 {
+			parts = append(parts, p.T(n.format, n.value))
+		}
+	}
+	if len(f.StopKeywords) > 0 {
+		parts = append(parts, p.T("%d stop keywords", len(f.StopKeywords)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (c *Charts) writeTemplate(filename, tpl string, data any) error {
+	f, err := os.Create(c.dir + filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return c.executeTemplate(f, tpl, data)
+}
+
+func (c *Charts) executeTemplate(w io.Writer, tpl string, data any) error {
+	t, err := pageTemplates.Clone()
+	if err != nil {
+		return err
+	}
+	return t.Funcs(template.FuncMap{"t": c.printer.T}).ExecuteTemplate(w, tpl, data)
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"dv/db"
+	"dv/internal/i18n"
+)
+
+func TestDashboardPages(t *testing.T) {
+	c := NewCharts(nil, t.TempDir()+"/", WithFormat(FormatSVG), WithLocale(i18n.Russian),
+		WithFilter(db.Filter{YearFrom: 1990, YearTo: 2000, Genre: "Drama"}))
+	report := &RunReport{Results: []ChartResult{
+		{Info: ChartInfo{Name: "bar", ChartType: "bar", Source: "GenreAverageMetrics", Output: "bar.html",
+			Description: "Average rating by genre (audience preference across genres)"}, Status: StatusOK, Rows: 19, Duration: 1234567 * time.Nanosecond},
+		{Info: ChartInfo{Name: "pie", ChartType: "pie", Source: "RuntimeSuccessSegments", Output: "pie.html"},
+			Status: StatusFailed, Err: errors.New(`query failed: <script>alert("x")</script>`)},
+	}}
+	view := c.dashboardView(report)
+
+	var dashboard bytes.Buffer
+	if err := c.executeTemplate(&dashboard, dashboardFile, view); err != nil {
+		t.Fatal(err)
+	}
+	page := dashboard.String()
+	for _, want := range []string{
+		`<html lang="ru">`,
+		"графиков: 1/2", // translated, with the OK count
+		"фильтр: годы 1990–2000, жанр Drama", // the run's filter
+		`<iframe src="bar.svg"`,              // output names follow the format
+		"Средний рейтинг по жанрам (предпочтения аудитории)",
+		"строк=19 · 1ms",
+		"FAILED: query failed: &lt;script&gt;", // errors are escaped
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("dashboard lacks %q:\n%s", want, page)
+		}
+	}
+	if strings.Contains(page, `src="pie.svg"`) || strings.Contains(page, `href="#pie"`) {
+		t.Error("dashboard embeds the failed chart")
+	}
+
+	var index bytes.Buffer
+	if err := c.executeTemplate(&index, indexFile, view); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<a href="bar.svg">bar</a>`, "<td>pie</td>", `class="FAILED"`} {
+		if !strings.Contains(index.String(), want) {
+			t.Errorf("index lacks %q:\n%s", want, index.String())
+		}
+	}
+}
+
+func TestGenerateAllChartsWritesPages(t *testing.T) {
+	repo, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir() + "/"
+	if err := NewCharts(repo, dir, WithRegistry(onlyScatter(t))).GenerateAllCharts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{dashboardFile, indexFile} {
+		b, err := os.ReadFile(dir + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Contains(b, []byte("scatter.html")) {
+			t.Errorf("%s does not link the generated chart", name)
+		}
+	}
+
+	// without the dashboard only the chart is written
+	dir = t.TempDir() + "/"
+	if err := NewCharts(repo, dir, WithRegistry(onlyScatter(t)), WithDashboard(false)).GenerateAllCharts(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(dir + dashboardFile); !os.IsNotExist(err) {
+		t.Errorf("dashboard written with WithDashboard(false): %v", err)
+	}
+}
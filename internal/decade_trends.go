@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const decadeTrendsFile = "decade_trends.html"
+
+type decadeTrendsChart struct{}
+
+func init() { Register(decadeTrendsChart{}) }
+
+func (decadeTrendsChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "decades",
+		Description: "Movies, budget, revenue, rating and runtime by decade (long-term trends)",
+		ChartType:   "Bar/Line",
+		Source:      "DecadeTrends",
+		Output:      decadeTrendsFile,
+	}
+}
+
+func (decadeTrendsChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.DecadeTrendsWithCount(ctx)
+}
+
+// WithDecadeIndex plots every decade metric as an index of its first decade
+// (= 100) instead of in its own unit, so growth rates compare directly.
+func WithDecadeIndex(on bool) Option {
+	return func(c *Charts) {
+		c.decadeIndex = on
+	}
+}
+
+// decadeTooltip lists every series of the hovered decade, money series with
+// the money formatter; kinds holds true for those by series index.
+const decadeTooltip = `function (ps) {
+	var kinds = %s, money = %s, number = %s;
+	return ps[0].axisValueLabel + ps.map(function (p) {
+		return '<br/>' + p.marker + p.seriesName + ': ' + (kinds[p.seriesIndex] ? money(p.value) : number(p.value));
+	}).join('');
+}`
+
+// DecadeTrendsWithCount draws two panels over the decades: movie counts as
+// bars with average budget and revenue as lines on a money axis, and below
+// them average rating and runtime, each on its own axis. With WithDecadeIndex
+// every metric is divided by its first decade's value instead and both panels
+// share one index axis.
+func (c *Charts) DecadeTrendsWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.DecadeTrends(ctx, c.filter.DecadeTrendsParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get decade trends: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no data for decade trends")
+	}
+	p := c.printer
+	decades := make([]string, 0, len(data))
+	metrics := [5][]float64{}
+	for _, d := range data {
+		decades = append(decades, p.T("%ds", d.Decade))
+		for i, v := range []float64{float64(d.MoviesCount), d.AvgBudget, d.AvgRevenue, d.AvgRating, d.AvgRuntime} {
+			metrics[i] = append(metrics[i], v)
+		}
+	}
+	names := []string{p.T("Movies"), p.T("Avg Budget"), p.T("Avg Revenue"), p.T("Avg Rating"), p.T("Avg Runtime")}
+	// y axes by metric: counts and money on the top panel, rating and runtime
+	// on the bottom one; in index mode each panel has a single axis
+	yAxes := []int{0, 1, 1, 2, 3}
+	money := []bool{false, true, true, false, false}
+	subtitle := p.T("decades=%d (%s-%s)", len(decades), decades[0], decades[len(decades)-1])
+	if c.decadeIndex {
+		for i := range metrics {
+			metrics[i] = indexOf(metrics[i])
+		}
+		yAxes = []int{0, 0, 0, 1, 1}
+		money = make([]bool, len(names))
+		subtitle = p.T("index, %s = 100", decades[0])
+	}
+
+	kinds, err := json.Marshal(money)
+	if err != nil {
+		return 0, err
+	}
+	label := func(fn string) *opts.AxisLabel {
+		return &opts.AxisLabel{Formatter: opts.FuncOpts(fn)}
+	}
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: p.T("Trends by Decade"), Subtitle: subtitle}),
+		charts.WithTooltipOpts(opts.Tooltip{
+			Show:      opts.Bool(true),
+			Trigger:   "axis",
+			Formatter: opts.FuncOpts(fmt.Sprintf(decadeTooltip, kinds, p.MoneyFormatterJS(), p.NumberFormatterJS(""))),
+		}),
+		// one pointer across both panels, so the tooltip shows all metrics
+		charts.WithAxisPointerOpts(&opts.AxisPointer{Link: []opts.AxisPointerLink{{XAxisIndex: []int{0, 1}}}}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), Top: "30"}),
+		charts.WithGridOpts(
+			opts.Grid{Top: "14%", Height: "46%"},
+			opts.Grid{Top: "70%", Height: "18%"},
+		),
+		charts.WithInitializationOpts(opts.Initialization{Height: "640px"}),
+	)
+	if c.decadeIndex {
+		bar.SetGlobalOptions(charts.WithYAxisOpts(opts.YAxis{Name: p.T("Index"), AxisLabel: label(p.NumberFormatterJS(""))}))
+		bar.ExtendYAxis(opts.YAxis{Name: p.T("Index"), GridIndex: 1, AxisLabel: label(p.NumberFormatterJS(""))})
+	} else {
+		bar.SetGlobalOptions(charts.WithYAxisOpts(opts.YAxis{Name: p.T("Movies"), AxisLabel: label(p.AxisFormatterJS())}))
+		bar.ExtendYAxis(
+			opts.YAxis{Name: p.T("Average, $"), Position: "right", AxisLabel: label(p.MoneyFormatterJS())},
+			opts.YAxis{Name: p.T("Avg Rating"), GridIndex: 1, AxisLabel: label(p.NumberFormatterJS(""))},
+			opts.YAxis{Name: p.T("Runtime (min)"), GridIndex: 1, Position: "right", AxisLabel: label(p.NumberFormatterJS(""))},
+		)
+	}
+	bar.ExtendXAxis(opts.XAxis{Name: p.T("Decade"), Type: "category", GridIndex: 1, Data: decades})
+
+	series := func(i int) []opts.LineData {
+		items := make([]opts.LineData, len(metrics[i]))
+		for j, v := range metrics[i] {
+			items[j] = opts.LineData{Value: v}
+		}
+		return items
+	}
+	counts := make([]opts.BarData, len(metrics[0]))
+	for j, v := range metrics[0] {
+		counts[j] = opts.BarData{Value: v}
+	}
+	bar.SetXAxis(decades).AddSeries(names[0], counts, charts.WithBarChartOpts(opts.BarChart{YAxisIndex: yAxes[0]}))
+
+	line := charts.NewLine()
+	line.SetXAxis(decades)
+	for i := 1; i < len(names); i++ {
+		xAxis := 0
+		if i >= 3 {
+			xAxis = 1
+		}
+		line.AddSeries(names[i], series(i), charts.WithLineChartOpts(opts.LineChart{XAxisIndex: xAxis, YAxisIndex: yAxes[i], Smooth: opts.Bool(true)}))
+	}
+	bar.Overlap(line)
+	return len(data), c.render(bar, decadeTrendsFile)
+}
+
+// indexOf scales values so the first non-zero one is 100; all zeros stay zero.
+func indexOf(values []float64) []float64 {
+	out := make([]float64, len(values))
+	for _, base := range values {
+		if base == 0 {
+			continue
+		}
+		for i, v := range values {
+			out[i] = v / base * 100
+		}
+		break
+	}
+	return out
+}
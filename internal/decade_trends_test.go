@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"dv/db"
+)
+
+func TestIndexOf(t *testing.T) {
+	for _, tc := range []struct {
+		in, want []float64
+	}{
+		{[]float64{50, 100, 25}, []float64{100, 200, 50}},
+		// the base is the first non-zero decade
+		{[]float64{0, 4, 2, 8}, []float64{0, 100, 50, 200}},
+		{[]float64{-2, -4, 1}, []float64{100, 200, -50}},
+		{[]float64{0, 0}, []float64{0, 0}},
+		{nil, []float64{}},
+	} {
+		if got := indexOf(tc.in); !slices.Equal(got, tc.want) {
+			t.Errorf("indexOf(%v) = %v, want %v", tc.in, got, tc.want)
+		}
+	}
+	in := []float64{2, 4}
+	indexOf(in)
+	if !slices.Equal(in, []float64{2, 4}) {
+		t.Errorf("indexOf changed its argument to %v", in)
+	}
+}
+
+func TestDecadeIndexMode(t *testing.T) {
+	repo, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, _ := repo.DecadeTrends(context.Background(), db.DecadeTrendsParams{})
+	for _, index := range []bool{false, true} {
+		dir := t.TempDir() + "/"
+		c := NewCharts(repo, dir, WithDecadeIndex(index))
+		if _, err := c.DecadeTrendsWithCount(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(dir + decadeTrendsFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subtitle := "index, " + c.printer.T("%ds", rows[0].Decade) + " = 100"
+		if got := strings.Contains(string(b), subtitle); got != index {
+			t.Errorf("index mode %v: subtitle %q shown = %v", index, subtitle, got)
+		}
+	}
+}
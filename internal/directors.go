@@ -0,0 +1,245 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const directorsFile = "directors.html"
+
+// directorPool is the row limit the chart queries with: every director with
+// enough movies, so both rankings are taken from the same complete list.
+const directorPool = 1000
+
+// defaultDirectors is the size of each ranking when the filter sets no top N,
+// matching the query's own default.
+const defaultDirectors = 15
+
+type directorsChart struct{}
+
+func init() { Register(directorsChart{}) }
+
+func (directorsChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "directors",
+		Description: "Top directors by budget, rating and box office with a sortable leaderboard",
+		ChartType:   "Bubble",
+		Source:      "DirectorPerformance",
+		Output:      directorsFile,
+	}
+}
+
+func (directorsChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.DirectorsWithCount(ctx)
+}
+
+// directorRow is a director as the page script sees it.
+type directorRow struct {
+	Name    string  `json:"name"`
+	Movies  int64   `json:"movies"`
+	Rating  float64 `json:"rating"`
+	Revenue float64 `json:"revenue"`
+	Budget  float64 `json:"budget"`
+	Box     float64 `json:"box"`
+	Size    int     `json:"size"` // bubble diameter in pixels
+}
+
+// directorView is one ranking: its label and the rows in rank order.
+type directorView struct {
+	Label string `json:"label"`
+	Rows  []int  `json:"rows"`
+}
+
+// directorColumn is a leaderboard column; Key names the directorRow field,
+// or "rank".
+type directorColumn struct {
+	Label string `json:"label"`
+	Key   string `json:"key"`
+	Money bool   `json:"money"`
+}
+
+// directorsScript adds the ranking selector above the chart and the
+// leaderboard below it. Choosing a ranking shows its directors on both;
+// clicking a column header sorts the table by it, again to reverse. The
+// tooltip shows a bubble's rank and every metric; value holds budget, rating,
+// box office, average revenue, movies and rank.
+const directorsScript = `(function () {
+	var chart = %%MY_ECHARTS%%;
+	var rows = %s, views = %s, columns = %s, money = %s, number = %s, style = %s, labels = %s, esc = %s;
+	var shown = [], sortKey = 'rank', desc = false;
+	chart.setOption({tooltip: {formatter: function (p) {
+		var v = p.value;
+		return '#' + v[5] + ' ' + esc(p.name) +
+			'<br/>' + esc(labels[0]) + ': ' + v[4] +
+			'<br/>' + esc(labels[1]) + ': ' + number(v[1]) +
+			'<br/>' + esc(labels[2]) + ': ' + money(v[0]) +
+			'<br/>' + esc(labels[3]) + ': ' + money(v[3]) +
+			'<br/>' + esc(labels[4]) + ': ' + money(v[2]);
+	}}});
+	var table = document.createElement('table');
+	table.style.cssText = 'border-collapse: collapse; margin: 8px; font-family: ' + style.font + '; color: ' + style.text + ';';
+	function cell(c, r) {
+		var v = r[c.key];
+		if (c.key === 'name' || c.key === 'rank' || c.key === 'movies') { return v; }
+		return c.money ? money(v) : number(v);
+	}
+	function render() {
+		shown.sort(function (a, b) {
+			var x = a[sortKey], y = b[sortKey];
+			var d = typeof x === 'string' ? x.localeCompare(y) : x - y;
+			return desc ? -d : d;
+		});
+		table.innerHTML = '';
+		var head = table.insertRow();
+		columns.forEach(function (c) {
+			var th = document.createElement('th');
+			th.textContent = c.label + (c.key === sortKey ? (desc ? ' ▼' : ' ▲') : '');
+			th.style.cssText = 'cursor: pointer; padding: 4px 10px; text-align: left; border-bottom: 2px solid ' + style.grid + ';';
+			th.onclick = function () {
+				desc = c.key === sortKey ? !desc : c.key !== 'name' && c.key !== 'rank';
+				sortKey = c.key;
+				render();
+			};
+			head.appendChild(th);
+		});
+		shown.forEach(function (r) {
+			var tr = table.insertRow();
+			columns.forEach(function (c) {
+				var td = tr.insertCell();
+				td.textContent = cell(c, r);
+				td.style.cssText = 'padding: 4px 10px; border-bottom: 1px solid ' + style.grid + ';';
+			});
+		});
+	}
+	function show(i) {
+		shown = views[i].rows.map(function (j, k) { return Object.assign({rank: k + 1}, rows[j]); });
+		chart.setOption({series: [{data: shown.map(function (r) {
+			return {name: r.name, value: [r.budget, r.rating, r.box, r.revenue, r.movies, r.rank], symbolSize: r.size};
+		})}]});
+		sortKey = 'rank';
+		desc = false;
+		render();
+	}
+	var select = document.createElement('select');
+	select.style.margin = '8px';
+	views.forEach(function (v, i) {
+		var o = document.createElement('option');
+		o.value = i;
+		o.textContent = v.label;
+		select.appendChild(o);
+	});
+	select.onchange = function () { show(+select.value); };
+	var dom = chart.getDom();
+	dom.parentNode.insertBefore(select, dom);
+	dom.parentNode.insertBefore(table, dom.nextSibling);
+	show(0);
+})();`
+
+// DirectorsWithCount places the top directors by average budget and average
+// rating, with bubbles sized by total box office. The page switches between
+// the top N by rating (the query's order) and by average revenue, and lists
+// the shown directors in a sortable table; the SVG has the rating view only.
+func (c *Charts) DirectorsWithCount(ctx context.Context) (int, error) {
+	params := c.filter.DirectorPerformanceParams()
+	params.TopN.Int32, params.TopN.Valid = directorPool, true
+	data, err := c.repo.DirectorPerformance(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get director performance: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no data for directors")
+	}
+	n := defaultDirectors
+	if c.filter.TopN > 0 {
+		n = c.filter.TopN
+	}
+	n = min(n, len(data))
+
+	rows := make([]directorRow, len(data))
+	maxBox := 0.0
+	for i, d := range data {
+		rows[i] = directorRow{
+			Name:    d.DirectorName.String,
+			Movies:  d.DirectedMovies,
+			Rating:  d.AvgRating,
+			Revenue: d.AvgRevenue,
+			Budget:  d.AvgBudget,
+			Box:     float64(d.TotalBoxOffice),
+		}
+		maxBox = max(maxBox, rows[i].Box)
+	}
+	for i := range rows {
+		// area, not diameter, proportional to box office
+		size := 8.0
+		if maxBox > 0 {
+			size += 42 * math.Sqrt(rows[i].Box/maxBox)
+		}
+		rows[i].Size = int(math.Round(size))
+	}
+	byRating := make([]int, len(rows))
+	for i := range byRating {
+		byRating[i] = i
+	}
+	byRevenue := slices.Clone(byRating)
+	slices.SortStableFunc(byRevenue, func(a, b int) int { return cmp.Compare(rows[b].Revenue, rows[a].Revenue) })
+
+	p := c.printer
+	views := []directorView{
+		{Label: p.T("Top %d by rating", n), Rows: byRating[:n]},
+		{Label: p.T("Top %d by revenue", n), Rows: byRevenue[:n]},
+	}
+	items := make([]opts.ScatterData, 0, n)
+	for k, i := range views[0].Rows {
+		r := rows[i]
+		items = append(items, opts.ScatterData{
+			Name:       r.Name,
+			Value:      []interface{}{r.Budget, r.Rating, r.Box, r.Revenue, r.Movies, k + 1},
+			SymbolSize: r.Size,
+		})
+	}
+
+	scatter := charts.NewScatter()
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    p.T("Director Performance"),
+			Subtitle: p.T("bubble size: total box office; directors=%s", p.Int(int64(len(data)))),
+		}),
+		charts.WithXAxisOpts(opts.XAxis{Name: p.T("Avg Budget"), Type: "value", AxisLabel: &opts.AxisLabel{Formatter: opts.FuncOpts(p.MoneyFormatterJS())}}),
+		charts.WithYAxisOpts(opts.YAxis{Name: p.T("Avg Rating"), Type: "value", Scale: opts.Bool(true)}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithInitializationOpts(opts.Initialization{Height: "560px"}),
+	)
+	scatter.AddSeries(p.T("Directors"), items).SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Position: "right", Formatter: "{b}"}),
+		charts.WithItemStyleOpts(opts.ItemStyle{Opacity: opts.Float(0.75)}),
+	)
+	if c.format == FormatSVG {
+		return len(data), c.render(scatter, directorsFile)
+	}
+
+	columns := []directorColumn{
+		{Label: "#", Key: "rank"},
+		{Label: p.T("Director"), Key: "name"},
+		{Label: p.T("Movies"), Key: "movies"},
+		{Label: p.T("Avg Rating"), Key: "rating"},
+		{Label: p.T("Avg Revenue"), Key: "revenue", Money: true},
+		{Label: p.T("Avg Budget"), Key: "budget", Money: true},
+		{Label: p.T("Total Box Office"), Key: "box", Money: true},
+	}
+	labels := []string{p.T("Movies"), p.T("Avg Rating"), p.T("Avg Budget"), p.T("Avg Revenue"), p.T("Total Box Office")}
+	var js [5][]byte
+	for i, v := range []any{rows, views, columns, map[string]string{"font": c.theme.Font, "text": c.theme.Text, "grid": c.theme.Grid}, labels} {
+		if js[i], err = json.Marshal(v); err != nil {
+			return 0, err
+		}
+	}
+	scatter.AddJSFuncs(fmt.Sprintf(directorsScript, js[0], js[1], js[2], p.MoneyFormatterJS(), p.NumberFormatterJS(""), js[3], js[4], escapeHTMLJS))
+	return len(data), c.render(scatter, directorsFile)
+}
@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dv/db"
+	"dv/internal/i18n"
+)
+
+// directorsRepo returns its rows, in the query's rating order, for any
+// DirectorPerformance call.
+type directorsRepo struct {
+	*db.Memory
+	rows []db.DirectorPerformanceRow
+}
+
+func (r directorsRepo) DirectorPerformance(ctx context.Context, _ db.DirectorPerformanceParams) ([]db.DirectorPerformanceRow, error) {
+	return r.rows, ctx.Err()
+}
+
+func director(name string, rating, revenue float64) db.DirectorPerformanceRow {
+	return db.DirectorPerformanceRow{DirectorName: pgtype.Text{String: name, Valid: true}, DirectedMovies: 3, AvgRating: rating, AvgRevenue: revenue, AvgBudget: revenue / 2, TotalBoxOffice: int64(revenue * 3)}
+}
+
+// scriptVar decodes the JSON a page script assigns to name ("var rows = ...").
+func scriptVar(t *testing.T, page, name string, v any) {
+	t.Helper()
+	loc := regexp.MustCompile(`\b` + name + ` = `).FindStringIndex(page)
+	if loc == nil {
+		t.Fatalf("page script sets no %s", name)
+	}
+	if err := json.NewDecoder(strings.NewReader(page[loc[1]:])).Decode(v); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
+
+func TestDirectorRankings(t *testing.T) {
+	rows := []db.DirectorPerformanceRow{
+		director("A", 8.5, 10e6),
+		director("B", 8.0, 300e6),
+		director("C", 7.5, 50e6),
+		director("D", 7.0, 300e6), // ties keep the rating order
+		director("E", 6.5, 900e6),
+	}
+	dir := t.TempDir() + "/"
+	c := NewCharts(directorsRepo{rows: rows}, dir, WithFilter(db.Filter{TopN: 3}))
+	if n, err := c.DirectorsWithCount(context.Background()); err != nil || n != len(rows) {
+		t.Fatalf("DirectorsWithCount = %d, %v; want %d rows", n, err, len(rows))
+	}
+	b, err := os.ReadFile(dir + directorsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var views []directorView
+	scriptVar(t, string(b), "views", &views)
+	if len(views) != 2 {
+		t.Fatalf("%d views, want rating and revenue", len(views))
+	}
+	for i, want := range [][]int{{0, 1, 2}, {4, 1, 3}} {
+		if !slices.Equal(views[i].Rows, want) {
+			t.Errorf("%s: rows %v, want %v", views[i].Label, views[i].Rows, want)
+		}
+	}
+	if views[1].Label != "Top 3 by revenue" {
+		t.Errorf("revenue view label %q", views[1].Label)
+	}
+}
+
+func TestDirectorsTooltipEscaping(t *testing.T) {
+	rows := []db.DirectorPerformanceRow{director(`<img src=x onerror=alert(1)> O'Brien "Jr"`, 8, 1e6)}
+	dir := t.TempDir() + "/"
+	c := NewCharts(directorsRepo{rows: rows}, dir, WithLocale(i18n.Russian))
+	if _, err := c.DirectorsWithCount(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(dir + directorsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	var labels []string
+	scriptVar(t, page, "labels", &labels)
+	if labels[0] != "Фильмы" {
+		t.Errorf("tooltip labels %q, want them translated", labels)
+	}
+	if !strings.Contains(page, "esc(p.name)") {
+		t.Error("the tooltip does not escape the director's name")
+	}
+	var shown []directorRow
+	scriptVar(t, page, "rows", &shown)
+	if shown[0].Name != rows[0].DirectorName.String {
+		t.Errorf("script name %q, want %q", shown[0].Name, rows[0].DirectorName.String)
+	}
+}
@@ -0,0 +1,87 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"dv/internal/svg"
+)
+
+// Format selects the renderer used for every chart in a run.
+type Format string
+
+const (
+	FormatHTML Format = "html" // interactive ECharts page
+	FormatSVG  Format = "svg"  // static image drawn server-side, for reports and emails
+)
+
+const (
+	svgWidth  = 900
+	svgHeight = 500
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case FormatHTML, FormatSVG:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown format %q (want %q or %q)", s, FormatHTML, FormatSVG)
+}
+
+// WithFormat switches chart output between HTML pages and static SVG images.
+func WithFormat(f Format) Option {
+	return func(c *Charts) {
+		c.format = f
+	}
+}
+
+// optionChart is implemented by every go-echarts chart: Validate fills in the
+// axis data and JSON returns the ECharts option the page would load.
+type optionChart interface {
+	ChartRenderer
+	Validate()
+	JSON() map[string]interface{}
+}
+
+// escapeHTMLJS escapes text for the markup of a tooltip, which ECharts
+// renders as HTML. Page scripts use it for data such as names and for
+// translated labels.
+const escapeHTMLJS = `function (s) {
+	return String(s).replace(/[&<>"']/g, function (ch) { return '&#' + ch.charCodeAt(0) + ';'; });
+}`
+
+// outputName maps a chart's .html file name to the one written in the current
+// format, so the dashboard links the files that actually exist.
+func (c *Charts) outputName(filename string) string {
+	if c.format == FormatSVG {
+		return strings.TrimSuffix(filename, ".html") + ".svg"
+	}
+	return filename
+}
+
+// renderSVG draws chart without a browser. Interactive extras added through
+// JavaScript (timelines, custom tooltips) are not part of the option and are
+// left out of the image.
+func renderSVG(w io.Writer, chart ChartRenderer, style svg.Style) error {
+	oc, ok := chart.(optionChart)
+	if !ok {
+		return fmt.Errorf("chart %T cannot be rendered as SVG", chart)
+	}
+	oc.Validate()
+	// round-trip through JSON so the renderer sees plain maps, slices and floats
+	// instead of go-echarts option structs
+	raw, err := json.Marshal(oc.JSON())
+	if err != nil {
+		return fmt.Errorf("failed to encode chart options: %w", err)
+	}
+	var option map[string]any
+	if err := json.Unmarshal(raw, &option); err != nil {
+		return fmt.Errorf("failed to decode chart options: %w", err)
+	}
+	if m, ok := chart.(*worldMap); ok {
+		return svg.RenderMap(w, option, m.shapes, svgWidth, svgHeight, style)
+	}
+	return svg.Render(w, option, svgWidth, svgHeight, style)
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const studiosFile = "studios_horizontal_bar.html"
+
+type studiosChart struct{}
+
+func init() { Register(studiosChart{}) }
+
+func (studiosChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "hbar",
+		Description: "Top studios by total revenue (market share of studios)",
+		ChartType:   "Horizontal Bar",
+		Source:      "StudioPerformance",
+		Output:      studiosFile,
+	}
+}
+
+func (studiosChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.HorizontalBarWithCount(ctx)
+}
+
+// HorizontalBar shows top studios by total revenue
+func (c *Charts) HorizontalBar(ctx context.Context) error {
+	_, err := c.HorizontalBarWithCount(ctx)
+	return err
+}
+func (c *Charts) HorizontalBarWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.StudioPerformance(ctx, c.filter.StudioPerformanceParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get studio performance: %w", err)
+	}
+	names := make([]string, 0, len(data))
+	values := make([]opts.BarData, 0, len(data))
+	for _, s := range data {
+		names = append(names, s.CompanyName.String)
+		values = append(values, opts.BarData{Value: s.TotalRevenue})
+	}
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: c.printer.T("Top Studios by Total Revenue")}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithYAxisOpts(opts.YAxis{Type: "category", Data: names, Name: c.printer.T("Studio")}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.printer.T("Total Revenue")}),
+	)
+	bar.AddSeries(c.printer.T("Revenue"), values).SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Position: "right"}),
+	)
+	return len(data), c.render(bar, studiosFile)
+}
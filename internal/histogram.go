@@ -0,0 +1,345 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"dv/pkg/stats"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const roiHistogramFile = "histogram.html"
+
+type roiHistogramChart struct{}
+
+func init() { Register(roiHistogramChart{}) }
+
+func (roiHistogramChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "roi_hist",
+		Description: "Distribution of ROI% across top profitable movies",
+		ChartType:   "Histogram",
+		Source:      "ListTopProfitableMovies",
+		Output:      roiHistogramFile,
+	}
+}
+
+func (roiHistogramChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.HistogramWithCount(ctx)
+}
+
+func (c *Charts) Histogram(ctx context.Context) error { // kept for backward compatibility (no row count)
+	_, err := c.HistogramWithCount(ctx)
+	return err
+}
+
+func (c *Charts) HistogramWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.ListTopProfitableMovies(ctx, c.movieSampleParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get profitable movies: %w", err)
+	}
+	values := make([]float64, 0, len(data))
+	for _, m := range data {
+		values = append(values, m.RoiPercent)
+	}
+	return c.HistogramOf(values, HistogramOptions{
+		Title:    "ROI% Histogram",
+		Axis:     "ROI %",
+		Unit:     "%",
+		ClipLow:  0.01,
+		ClipHigh: 0.99,
+		Overlay:  OverlayDensity,
+	}, roiHistogramFile)
+}
+
+// BinStrategy selects how HistogramOf chooses the number and width of bins.
+type BinStrategy string
+
+const (
+	BinFreedmanDiaconis BinStrategy = "fd"      // 2·IQR/∛n, falls back to Sturges when IQR is 0
+	BinSturges          BinStrategy = "sturges" // ⌈log2 n⌉+1 bins
+	BinScott            BinStrategy = "scott"   // 3.49·σ/∛n
+	BinSqrt             BinStrategy = "sqrt"    // ⌈√n⌉ bins
+	BinFixedCount       BinStrategy = "count"   // HistogramOptions.Bins bins
+	BinFixedWidth       BinStrategy = "width"   // bins of HistogramOptions.Width
+)
+
+// Overlay is an optional line drawn over the histogram bars on a second y axis.
+type Overlay string
+
+const (
+	OverlayNone    Overlay = ""
+	OverlayDensity Overlay = "density" // bin count / (n · width)
+	OverlayKDE     Overlay = "kde"     // Gaussian kernel density, Silverman bandwidth
+)
+
+// HistogramOptions configures HistogramOf. The zero value bins with
+// Freedman–Diaconis into 5–60 bins without clipping. Title, Axis and Unit are
+// English and translated through the message catalog.
+type HistogramOptions struct {
+	Title string
+	Axis  string // x axis name
+	Unit  string // appended to mean/median in the subtitle
+
+	Binning BinStrategy
+	Bins    int     // BinFixedCount
+	Width   float64 // BinFixedWidth, in (log10 when LogScale) value units
+	MinBins int     // clamp for computed strategies, default 5
+	MaxBins int     // clamp for computed strategies, default 60
+
+	// ClipLow/ClipHigh are quantiles bounding the binned range; values outside
+	// are dropped. 0 and 1 (or unset) disable clipping on that side.
+	ClipLow  float64
+	ClipHigh float64
+
+	// LogScale bins log10 of the values; non-positive values are dropped.
+	LogScale bool
+	Overlay  Overlay
+}
+
+// maxHistogramBins bounds every strategy, so a tiny fixed width or a huge
+// fixed count fails instead of allocating without limit.
+const maxHistogramBins = 1000
+
+// HistogramBins is the result of binning a series.
+type HistogramBins struct {
+	Edges  []float64 // len(Counts)+1 bin edges, in binning space
+	Counts []int
+	Width  float64
+	Total  int // values considered (before clipping), used for density
+}
+
+func (h HistogramBins) Mid(i int) float64 { return (h.Edges[i] + h.Edges[i+1]) / 2 }
+
+// BinValues bins xs according to o. It does not modify xs.
+func BinValues(xs []float64, o HistogramOptions) (HistogramBins, error) {
+	values := make([]float64, 0, len(xs))
+	for _, v := range xs {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		if o.LogScale {
+			if v <= 0 {
+				continue
+			}
+			v = math.Log10(v)
+		}
+		values = append(values, v)
+	}
+	if len(values) == 0 {
+		return HistogramBins{}, fmt.Errorf("no values to bin")
+	}
+
+	hi := o.ClipHigh
+	if hi == 0 {
+		hi = 1
+	}
+	clipped := stats.NewSample(values).Clip(o.ClipLow, hi)
+	minV, maxV := clipped.Min(), clipped.Max()
+	if maxV == minV {
+		maxV = minV + 1
+	}
+	span := maxV - minV
+	n := float64(clipped.Len())
+
+	bins := 0
+	width := 0.0
+	switch o.Binning {
+	case BinFixedCount:
+		if o.Bins <= 0 {
+			return HistogramBins{}, fmt.Errorf("fixed-count binning needs Bins > 0")
+		}
+		bins = o.Bins
+	case BinFixedWidth:
+		if o.Width <= 0 {
+			return HistogramBins{}, fmt.Errorf("fixed-width binning needs Width > 0")
+		}
+		width = o.Width
+		// checked before converting, the ratio may not fit an int
+		if span/width > maxHistogramBins {
+			return HistogramBins{}, fmt.Errorf("width %g gives more than %d bins over %g", width, maxHistogramBins, span)
+		}
+		bins = max(1, int(math.Ceil(span/width)))
+	case BinSturges:
+		bins = sturgesBins(n)
+	case BinSqrt:
+		bins = int(math.Ceil(math.Sqrt(n)))
+	case BinScott:
+		if sd := stats.StdDev(clipped.Values()); sd > 0 {
+			width = 3.49 * sd / math.Cbrt(n)
+		}
+	case BinFreedmanDiaconis, "":
+		if iqr := clipped.IQR(); iqr > 0 {
+			width = 2 * iqr / math.Cbrt(n)
+		}
+	default:
+		return HistogramBins{}, fmt.Errorf("unknown bin strategy %q", o.Binning)
+	}
+	if bins == 0 {
+		if width > 0 {
+			bins = int(math.Ceil(span / width))
+		} else { // degenerate spread, fallback Sturges
+			bins = sturgesBins(n)
+		}
+	}
+	if o.Binning != BinFixedCount && o.Binning != BinFixedWidth {
+		minBins, maxBins := o.MinBins, o.MaxBins
+		if minBins <= 0 {
+			minBins = 5
+		}
+		if maxBins <= 0 {
+			maxBins = 60 // cap to keep chart readable
+		}
+		bins = max(minBins, min(bins, maxBins))
+	}
+	if bins > maxHistogramBins {
+		return HistogramBins{}, fmt.Errorf("%d bins exceed the limit of %d", bins, maxHistogramBins)
+	}
+	if o.Binning != BinFixedWidth {
+		width = span / float64(bins)
+	}
+
+	h := HistogramBins{
+		Edges:  make([]float64, bins+1),
+		Counts: make([]int, bins),
+		Width:  width,
+		Total:  len(values),
+	}
+	for i := range h.Edges {
+		h.Edges[i] = minV + float64(i)*width
+	}
+	h.Edges[bins] = math.Max(h.Edges[bins], maxV)
+	for _, v := range values { // use original distribution (not only clipped) but bin into clipped range
+		if v < minV || v > maxV {
+			continue
+		}
+		idx := int((v - minV) / width)
+		if idx >= bins {
+			idx = bins - 1
+		}
+		h.Counts[idx]++
+	}
+	return h, nil
+}
+
+// HistogramOf renders a histogram of xs to filename and returns the number of
+// values considered.
+func (c *Charts) HistogramOf(xs []float64, o HistogramOptions, filename string) (int, error) {
+	h, err := BinValues(xs, o)
+	if err != nil {
+		return 0, err
+	}
+	values := xs
+	if o.LogScale {
+		values = make([]float64, 0, len(xs))
+		for _, v := range xs {
+			if v > 0 {
+				values = append(values, v)
+			}
+		}
+	}
+	avg := stats.Mean(values)
+	median := stats.Median(values)
+	medianX := median
+	if o.LogScale {
+		medianX = math.Log10(median)
+	}
+
+	bins := len(h.Counts)
+	barData := make([]opts.BarData, 0, bins)
+	for i, cnt := range h.Counts {
+		binMid := h.Mid(i)
+		start, end := h.Edges[i], h.Edges[i+1]
+		if o.LogScale {
+			start, end = math.Pow(10, start), math.Pow(10, end)
+		}
+		color := c.theme.Color(0)
+		if binMid >= medianX {
+			color = c.theme.Color(1)
+		}
+		barData = append(barData, opts.BarData{Value: []interface{}{binMid, cnt, start, end}, ItemStyle: &opts.ItemStyle{Color: color}})
+	}
+
+	p := c.printer
+	axis := p.T(o.Axis)
+	if o.LogScale {
+		axis = p.T("log10 %s", axis)
+	}
+	strategy := o.Binning
+	if strategy == "" {
+		strategy = BinFreedmanDiaconis
+	}
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: p.T(o.Title), Subtitle: p.T("n=%s mean=%s%s median=%s%s bins=%d (%s) width≈%s", p.Int(int64(h.Total)), p.Compact(avg), p.T(o.Unit), p.Compact(median), p.T(o.Unit), bins, strategy, p.Compact(h.Width))}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Formatter: "{b}: {c}"}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithXAxisOpts(opts.XAxis{Name: axis, Type: "value"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: p.T("Count"), Position: "left"}),
+	)
+	bar.AddSeries(p.T("Count"), barData).SetSeriesOptions(
+		charts.WithBarChartOpts(opts.BarChart{BarGap: "-100%", BarCategoryGap: "0%"}),
+	)
+
+	if o.Overlay != OverlayNone {
+		bar.ExtendYAxis(opts.YAxis{Name: p.T("Density"), Position: "right"})
+		line := charts.NewLine()
+		line.AddSeries(p.T(overlayName(o.Overlay)), overlayData(h, o.Overlay, values, o.LogScale), charts.WithLineChartOpts(opts.LineChart{YAxisIndex: 1})).SetSeriesOptions(
+			charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(true)}),
+			charts.WithAreaStyleOpts(opts.AreaStyle{Opacity: opts.Float(0.15)}),
+			// median & average vertical lines via markLine on the overlay series
+			charts.WithMarkLineNameXAxisItemOpts(
+				opts.MarkLineNameXAxisItem{Name: p.T("Median"), XAxis: medianX},
+				opts.MarkLineNameXAxisItem{Name: p.T("Mean"), XAxis: scaleX(avg, o.LogScale)},
+			),
+		)
+		bar.Overlap(line)
+	}
+	return h.Total, c.render(bar, filename)
+}
+
+func overlayName(o Overlay) string {
+	if o == OverlayKDE {
+		return "KDE"
+	}
+	return "Density"
+}
+
+func overlayData(h HistogramBins, o Overlay, values []float64, logScale bool) []opts.LineData {
+	out := make([]opts.LineData, 0, len(h.Counts))
+	if o == OverlayKDE {
+		xs := values
+		if logScale {
+			xs = make([]float64, len(values))
+			for i, v := range values {
+				xs[i] = math.Log10(v)
+			}
+		}
+		bw := stats.SilvermanBandwidth(xs)
+		for i := range h.Counts {
+			x := h.Mid(i)
+			out = append(out, opts.LineData{Value: []interface{}{x, stats.GaussianKDE(xs, bw, x)}})
+		}
+		return out
+	}
+	total := float64(h.Total)
+	for i, cnt := range h.Counts {
+		out = append(out, opts.LineData{Value: []interface{}{h.Mid(i), float64(cnt) / (total * h.Width)}})
+	}
+	return out
+}
+
+func scaleX(v float64, logScale bool) float64 {
+	if logScale && v > 0 {
+		return math.Log10(v)
+	}
+	return v
+}
+
+// --- histogram helpers ---
+func sturgesBins(n float64) int {
+	return int(math.Ceil(1 + math.Log2(n)))
+}
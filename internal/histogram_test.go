@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func sequence(n int) []float64 {
+	xs := make([]float64, n)
+	for i := range xs {
+		xs[i] = float64(i + 1)
+	}
+	return xs
+}
+
+// checkBins verifies the invariants every binning keeps: ascending edges one
+// width apart covering the values, and every value counted once.
+func checkBins(t *testing.T, name string, h HistogramBins, counted int) {
+	t.Helper()
+	if len(h.Edges) != len(h.Counts)+1 {
+		t.Errorf("%s: %d edges for %d bins", name, len(h.Edges), len(h.Counts))
+	}
+	for i := 1; i < len(h.Edges)-1; i++ {
+		if math.Abs(h.Edges[i]-h.Edges[i-1]-h.Width) > 1e-9 {
+			t.Errorf("%s: edges %v are not %g apart", name, h.Edges, h.Width)
+			break
+		}
+	}
+	sum := 0
+	for _, c := range h.Counts {
+		sum += c
+	}
+	if sum != counted {
+		t.Errorf("%s: counted %d values, want %d", name, sum, counted)
+	}
+}
+
+func TestBinStrategies(t *testing.T) {
+	xs := sequence(1000) // span 999, IQR 499.5, sd ≈ 288.82
+	for _, tc := range []struct {
+		o     HistogramOptions
+		bins  int
+		width float64
+	}{
+		{HistogramOptions{}, 10, 99.9},                                    // fd: 2·499.5/∛1000 = 99.9
+		{HistogramOptions{Binning: BinFreedmanDiaconis}, 10, 99.9},        //
+		{HistogramOptions{Binning: BinSturges}, 11, 999.0 / 11},           // ⌈log2 1000⌉+1
+		{HistogramOptions{Binning: BinScott}, 10, 99.9},                   // 3.49·288.82/10 ≈ 100.8
+		{HistogramOptions{Binning: BinSqrt}, 32, 999.0 / 32},              // ⌈√1000⌉
+		{HistogramOptions{Binning: BinSqrt, MaxBins: 20}, 20, 999 / 20.0}, // clamped
+		{HistogramOptions{Binning: BinFixedCount, Bins: 7}, 7, 999.0 / 7},
+		{HistogramOptions{Binning: BinFixedCount, Bins: 200}, 200, 999.0 / 200}, // no 60-bin clamp
+		{HistogramOptions{Binning: BinFixedWidth, Width: 250}, 4, 250},
+	} {
+		name := string(tc.o.Binning)
+		h, err := BinValues(xs, tc.o)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		if len(h.Counts) != tc.bins || math.Abs(h.Width-tc.width) > 1e-9 {
+			t.Errorf("%s: %d bins of %g, want %d of %g", name, len(h.Counts), h.Width, tc.bins, tc.width)
+		}
+		if h.Total != 1000 {
+			t.Errorf("%s: total %d, want 1000", name, h.Total)
+		}
+		checkBins(t, name, h, 1000)
+	}
+
+	// the last fixed-width bin may stick out past the largest value
+	h, _ := BinValues(xs, HistogramOptions{Binning: BinFixedWidth, Width: 250})
+	if h.Edges[0] != 1 || h.Edges[4] != 1001 {
+		t.Errorf("fixed-width edges %v, want 1 to 1001", h.Edges)
+	}
+}
+
+func TestBinDegenerate(t *testing.T) {
+	for _, xs := range [][]float64{{5, 5, 5, 5}, {42}} {
+		for _, o := range []HistogramOptions{
+			{Binning: BinFreedmanDiaconis}, // IQR 0 falls back to Sturges
+			{Binning: BinSturges},
+			{Binning: BinScott}, // σ 0 falls back to Sturges
+			{Binning: BinSqrt},
+			{Binning: BinFixedCount, Bins: 3},
+			{Binning: BinFixedWidth, Width: 0.25},
+		} {
+			name := string(o.Binning)
+			h, err := BinValues(xs, o)
+			if err != nil {
+				t.Errorf("%s of %v: %v", name, xs, err)
+				continue
+			}
+			checkBins(t, name, h, len(xs))
+			// a zero spread is binned over one unit from the value
+			if h.Edges[0] != xs[0] || h.Edges[len(h.Edges)-1] < xs[0]+1 || h.Counts[0] != len(xs) {
+				t.Errorf("%s of %v: edges %v counts %v, want all in the first bin from %g", name, xs, h.Edges, h.Counts, xs[0])
+			}
+			if o.Binning != BinFixedCount && o.Binning != BinFixedWidth && len(h.Counts) != 5 {
+				t.Errorf("%s of %v: %d bins, want the minimum of 5", name, xs, len(h.Counts))
+			}
+		}
+	}
+}
+
+func TestBinClipAndLog(t *testing.T) {
+	xs := append(sequence(98), 1e6, math.NaN(), math.Inf(1))
+	h, err := BinValues(xs, HistogramOptions{Binning: BinFixedCount, Bins: 10, ClipHigh: 0.98})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := h.Edges[len(h.Edges)-1]; last >= 1e6 {
+		t.Errorf("clipped range ends at %g, want the outlier left out", last)
+	}
+	if h.Total != 99 {
+		t.Errorf("total %d, want NaN and Inf dropped", h.Total)
+	}
+
+	h, err = BinValues([]float64{-5, 0, 10, 100, 1000}, HistogramOptions{Binning: BinFixedCount, Bins: 2, LogScale: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Edges[0] != 1 || h.Edges[2] != 3 || h.Counts[0] != 1 || h.Counts[1] != 2 || h.Total != 3 {
+		t.Errorf("log bins %v %v (total %d), want decades 1–3 of the positive values", h.Edges, h.Counts, h.Total)
+	}
+}
+
+func TestBinErrors(t *testing.T) {
+	xs := sequence(1000)
+	for _, tc := range []struct {
+		name string
+		xs   []float64
+		o    HistogramOptions
+		want string
+	}{
+		{"no values", nil, HistogramOptions{}, "no values"},
+		{"only non-positive on log", []float64{0, -1}, HistogramOptions{LogScale: true}, "no values"},
+		{"unknown strategy", xs, HistogramOptions{Binning: "rice"}, "unknown bin strategy"},
+		{"zero count", xs, HistogramOptions{Binning: BinFixedCount}, "Bins > 0"},
+		{"zero width", xs, HistogramOptions{Binning: BinFixedWidth}, "Width > 0"},
+		{"huge count", xs, HistogramOptions{Binning: BinFixedCount, Bins: 1_000_000}, "limit of 1000"},
+		{"tiny width", xs, HistogramOptions{Binning: BinFixedWidth, Width: 0.01}, "more than 1000 bins"},
+		{"vanishing width", xs, HistogramOptions{Binning: BinFixedWidth, Width: 1e-300}, "more than 1000 bins"},
+		{"huge max", xs, HistogramOptions{Binning: BinSqrt, MinBins: 5000, MaxBins: 5000}, "limit of 1000"},
+	} {
+		if _, err := BinValues(tc.xs, tc.o); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: BinValues = %v, want error containing %q", tc.name, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package i18n
+
+// catalog maps English messages to their translations. English needs no
+// entries; a message missing from a locale is shown in English.
+var catalog = map[Locale]map[string]string{
+	Russian: {
+		// chart descriptions
+		"Average rating by genre (audience preference across genres)":                   "Средний рейтинг по жанрам (предпочтения аудитории)",
+		"Top studios by total revenue (market share of studios)":                        "Топ студий по суммарным сборам (доля рынка студий)",
+		"Distribution of ROI% across top profitable movies":                             "Распределение ROI% среди самых прибыльных фильмов",
+		"Average revenue trend by year (temporal performance)":                          "Динамика средних сборов по годам",
+		"Distribution of movie budgets (log-scaled)":                                    "Распределение бюджетов фильмов (логарифмическая шкала)",
+		"Distribution of movie revenues (log-scaled)":                                   "Распределение сборов фильмов (логарифмическая шкала)",
+		"Distribution of movie runtimes":                                                "Распределение длительности фильмов",
+		"Distribution of average user ratings":                                          "Распределение средних пользовательских оценок",
+		"Distribution of TMDB popularity scores (log-scaled)":                           "Распределение популярности TMDB (логарифмическая шкала)",
+		"Distribution of movies by runtime duration segment (commercial success proxy)": "Распределение фильмов по длительности (косвенный показатель успеха)",
+		"Budget vs Revenue with ROI color (capital efficiency)":                         "Бюджет и сборы с цветом по ROI (эффективность вложений)",
+		"Monthly releases per year with a year slider (release seasonality)":            "Релизы по месяцам с выбором года (сезонность выхода)",
+		"Number of movies by release year (output volume over time)":                    "Число фильмов по году выхода (объём производства)",
+		"Film production by country on a world map (movies, budget, revenue, rating)":   "Кинопроизводство по странам на карте мира (фильмы, бюджет, сборы, рейтинг)",
+		"Movies, budget, revenue, rating and runtime by decade (long-term trends)":      "Фильмы, бюджет, сборы, рейтинг и длительность по десятилетиям (долгосрочные тренды)",
+		"Top directors by budget, rating and box office with a sortable leaderboard":    "Лучшие режиссёры по бюджету, рейтингу и сборам с сортируемой таблицей",
+		"Most frequent keywords sized by movie count and colored by average rating":     "Самые частые ключевые слова: размер — число фильмов, цвет — средний рейтинг",
+		"Movies per year for the most frequent keywords (rising and falling themes)":    "Фильмы по годам для самых частых ключевых слов (растущие и угасающие темы)",
+		"Original and spoken languages by movie count, colored by revenue or rating":    "Языки оригинала и разговорные языки по числу фильмов с цветом по сборам или рейтингу",
+
+		// titles, axes and series
+		"Average Rating by Genre":      "Средний рейтинг по жанрам",
+		"Genre":                        "Жанр",
+		"Avg Rating":                   "Средний рейтинг",
+		"Top Studios by Total Revenue": "Топ студий по суммарным сборам",
+		"Studio":                       "Студия",
+		"Total Revenue":                "Суммарные сборы",
+		"Revenue":                      "Сборы",
+		"Average Revenue by Year":      "Средние сборы по годам",
+		"Year":                         "Год",
+		"Average Revenue":              "Средние сборы",
+		"Avg Revenue":                  "Средние сборы",
+		"Avg Budget":                   "Средний бюджет",
+		"Film Production by Country":   "Кинопроизводство по странам",
+		"Trends by Decade":             "Тренды по десятилетиям",
+		"%ds":                          "%d-е",
+		"Decade":                       "Десятилетие",
+		"Avg Runtime":                  "Средняя длительность",
+		"Average, $":                   "Среднее, $",
+		"Index":                        "Индекс",
+		"Director Performance":         "Эффективность режиссёров",
+		"Directors":                    "Режиссёры",
+		"Director":                     "Режиссёр",
+		"Total Box Office":             "Суммарные сборы в прокате",
+		"Top %d by rating":             "Топ-%d по рейтингу",
+		"Top %d by revenue":            "Топ-%d по сборам",
+		"Movie Keywords":               "Ключевые слова фильмов",
+		"Keywords":                     "Ключевые слова",
+		"Movie Languages by Role":      "Языки фильмов по роли",
+		"Languages":                    "Языки",
+		"Original":                     "Язык оригинала",
+		"Spoken":                       "Разговорные языки",
+		"Keyword Trends by Year":       "Ключевые слова по годам",
+		"Movie Duration Distribution":  "Распределение фильмов по длительности",
+		"Duration Segments":            "Сегменты длительности",
+		"Short (<90 min)":              "Короткие (<90 мин)",
+		"Medium (90-120 min)":          "Средние (90–120 мин)",
+		"Long (121-150 min)":           "Длинные (121–150 мин)",
+		"Very long (>150 min)":         "Очень длинные (>150 мин)",
+		"Movies Released per Year":     "Выпуск фильмов по годам",
+		"Movies":                       "Фильмы",
+		"Avg":                          "Среднее",
+		"Trend":                        "Тренд",
+		"Monthly Releases":             "Релизы по месяцам",
+		"Monthly Releases %d":          "Релизы по месяцам, %d",
+		"Movies without Release Date":  "Фильмы без даты выхода",
+		"Month":                        "Месяц",
+		"Unknown":                      "Неизвестно",
+		"Budget vs Revenue (Top Profitable Movies)": "Бюджет и сборы (самые прибыльные фильмы)",
+		"Budget":               "Бюджет",
+		"Budget ($)":           "Бюджет ($)",
+		"Revenue ($)":          "Сборы ($)",
+		"ROI %":                "ROI, %",
+		"Break-even":           "Окупаемость",
+		"Outliers":             "Выбросы",
+		"ROI% Histogram":       "Гистограмма ROI, %",
+		"Budget Histogram":     "Гистограмма бюджетов",
+		"Revenue Histogram":    "Гистограмма сборов",
+		"Runtime Histogram":    "Гистограмма длительности",
+		"Runtime (min)":        "Длительность (мин)",
+		" min":                 " мин",
+		"Rating Histogram":     "Гистограмма рейтингов",
+		"Vote Average":         "Средняя оценка",
+		"Popularity Histogram": "Гистограмма популярности",
+		"Popularity":           "Популярность",
+		"Count":                "Количество",
+		"Density":              "Плотность",
+		"Median":               "Медиана",
+		"Mean":                 "Среднее",
+
+		// months
+		"Jan": "Янв", "Feb": "Фев", "Mar": "Мар", "Apr": "Апр", "May": "Май", "Jun": "Июн",
+		"Jul": "Июл", "Aug": "Авг", "Sep": "Сен", "Oct": "Окт", "Nov": "Ноя", "Dec": "Дек",
+
+		// subtitles
+		"years=%d(%d-%d) total=%s avg≈%s max=%s":           "лет=%d (%d–%d) всего=%s в среднем≈%s макс.=%s",
+		"years=%d (%d-%d)":                                 "лет=%d (%d–%d)",
+		"countries=%d":                                     "стран=%d",
+		"decades=%d (%s-%s)":                               "десятилетий=%d (%s–%s)",
+		"index, %s = 100":                                  "индекс, %s = 100",
+		"bubble size: total box office; directors=%s":      "размер пузырька: суммарные сборы; режиссёров=%s",
+		"size: movies; color: avg rating %s–%s":            "размер: фильмы; цвет: средний рейтинг %s–%s",
+		"size: movies; color: avg revenue %s–%s":           "размер: фильмы; цвет: средние сборы %s–%s",
+		"keywords=%d years=%d (%d-%d)":                     "ключевых слов=%d лет=%d (%d–%d)",
+		" undated=%s shown as Unknown":                     " без даты=%s показаны как «Неизвестно»",
+		" undated=%s excluded":                             " без даты=%s исключены",
+		"n=%s trend: %s R²=%s":                             "n=%s тренд: %s R²=%s",
+		"revenue ≈ %s·budget^%s":                           "сборы ≈ %s·бюджет^%s",
+		"revenue ≈ %s·budget %s %s":                        "сборы ≈ %s·бюджет %s %s",
+		"n=%s mean=%s%s median=%s%s bins=%d (%s) width≈%s": "n=%s среднее=%s%s медиана=%s%s интервалов=%d (%s) ширина≈%s",
+
+		// dashboard and index pages
+		"Movie Analytics": "Аналитика фильмов",
+		"Generated":       "Сформировано",
+		"%d/%d charts":    "графиков: %d/%d",
+		"dashboard":       "дашборд",
+		"index":           "оглавление",
+		"source":          "источник",
+		"rows":            "строк",
+		"open":            "открыть",
+		"Chart":           "График",
+		"Type":            "Тип",
+		"Status":          "Статус",
+		"Rows":            "Строк",
+		"Time":            "Время",
+		"Description":     "Описание",
+
+		// active filter
+		"filter":             "фильтр",
+		"years %d–%d":        "годы %d–%d",
+		"from %d":            "с %d года",
+		"until %d":           "по %d год",
+		"genre %s":           "жанр %s",
+		"country %s":         "страна %s",
+		"language %s":        "язык %s",
+		"at least %d votes":  "не менее %d голосов",
+		"at least %d movies": "не менее %d фильмов",
+		"top %d":             "топ %d",
+		"%d stop keywords":   "стоп-слов: %d",
+	},
+}
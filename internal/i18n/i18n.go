@@ -0,0 +1,170 @@
+// Package i18n holds the message catalog for user-visible chart strings and
+// locale-aware number formatting. Messages are keyed by their English text, so
+// a missing translation falls back to English instead of an opaque ID.
+package i18n
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+type Locale string
+
+const (
+	English Locale = "en"
+	Russian Locale = "ru"
+)
+
+func ParseLocale(s string) (Locale, error) {
+	switch l := Locale(s); l {
+	case English, Russian:
+		return l, nil
+	}
+	return "", fmt.Errorf("unknown locale %q (want %q or %q)", s, English, Russian)
+}
+
+type numberFormat struct {
+	group   string // thousands separator
+	decimal string
+	// compact magnitude suffixes for 1e3, 1e6 and 1e9
+	thousand, million, billion string
+}
+
+var numberFormats = map[Locale]numberFormat{
+	English: {group: ",", decimal: ".", thousand: "K", million: "M", billion: "B"},
+	Russian: {group: " ", decimal: ",", thousand: " тыс.", million: " млн", billion: " млрд"},
+}
+
+// Printer translates messages and formats numbers for one locale.
+type Printer struct {
+	locale   Locale
+	messages map[string]string
+	num      numberFormat
+}
+
+func NewPrinter(l Locale) *Printer {
+	nf, ok := numberFormats[l]
+	if !ok {
+		l, nf = English, numberFormats[English]
+	}
+	return &Printer{locale: l, messages: catalog[l], num: nf}
+}
+
+func (p *Printer) Locale() Locale { return p.locale }
+
+// T translates msg and, with args, formats it like fmt.Sprintf. Translations
+// must keep the verbs of the English text in the same order.
+func (p *Printer) T(msg string, args ...any) string {
+	if tr, ok := p.messages[msg]; ok {
+		msg = tr
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Int formats n with thousands separators: 1,234,567 or 1 234 567 (with
+// non-breaking spaces).
+func (p *Printer) Int(n int64) string {
+	return p.Number(float64(n), 0)
+}
+
+// Number formats v with prec decimals and thousands separators.
+func (p *Printer) Number(v float64, prec int) string {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	s := strconv.FormatFloat(math.Abs(v), 'f', prec, 64)
+	whole, frac, _ := strings.Cut(s, ".")
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, d := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(p.num.group)
+		}
+		b.WriteRune(d)
+	}
+	if frac != "" {
+		b.WriteString(p.num.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Compact abbreviates large magnitudes to one decimal: 12.5M, 1.2B or
+// 12,5 млн, 1,2 млрд. Values below ten thousand are written in full. A value
+// that rounds up to a thousand of one unit is written in the next (1M, not
+// 1,000K).
+func (p *Printer) Compact(v float64) string {
+	a := math.Abs(v)
+	switch {
+	case a >= 999.95e6:
+		return p.trimmed(v/1e9, 1) + p.num.billion
+	case a >= 999.95e3:
+		return p.trimmed(v/1e6, 1) + p.num.million
+	case a >= 1e4:
+		return p.trimmed(v/1e3, 1) + p.num.thousand
+	case a == math.Trunc(a) || a >= 100:
+		return p.Number(v, 0)
+	default:
+		return p.trimmed(v, 2)
+	}
+}
+
+// Money is Compact with a dollar sign placed the way the locale writes it.
+func (p *Printer) Money(v float64) string {
+	if p.locale == Russian {
+		return p.Compact(v) + " $"
+	}
+	if v < 0 {
+		return "-$" + p.Compact(-v)
+	}
+	return "$" + p.Compact(v)
+}
+
+// trimmed formats with up to prec decimals, dropping trailing zeros.
+func (p *Printer) trimmed(v float64, prec int) string {
+	s := p.Number(v, prec)
+	if prec > 0 {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), p.num.decimal)
+	}
+	return s
+}
+
+// AxisFormatterJS is an ECharts axis label formatter that abbreviates values
+// the same way Compact does, for charts rendered in the browser.
+func (p *Printer) AxisFormatterJS() string {
+	return p.compactJS("", "")
+}
+
+// MoneyFormatterJS is AxisFormatterJS with the dollar sign of Money.
+func (p *Printer) MoneyFormatterJS() string {
+	if p.locale == Russian {
+		return p.compactJS("", p.num.group+"$")
+	}
+	return p.compactJS("$", "")
+}
+
+// NumberFormatterJS writes values in full with the locale's separators,
+// followed by suffix (e.g. "%").
+func (p *Printer) NumberFormatterJS(suffix string) string {
+	return fmt.Sprintf(`function (v) { return v.toLocaleString('%s', {maximumFractionDigits: 2}) + '%s'; }`, p.locale, suffix)
+}
+
+// The formatters use single quotes only: go-echarts embeds them in JSON
+// without unescaping.
+func (p *Printer) compactJS(prefix, suffix string) string {
+	return fmt.Sprintf(`function (v) {
+	var a = Math.abs(v), units = [[999.95e6, 1e9, '%s'], [999.95e3, 1e6, '%s'], [1e4, 1e3, '%s']], s = '';
+	for (var i = 0; i < units.length && s === ''; i++) {
+		if (a >= units[i][0]) { s = (v / units[i][1]).toLocaleString('%s', {maximumFractionDigits: 1}) + units[i][2]; }
+	}
+	if (s === '') { s = v.toLocaleString('%s', {maximumFractionDigits: 2}); }
+	return '%s' + s + '%s';
+}`, p.num.billion, p.num.million, p.num.thousand, p.locale, p.locale, prefix, suffix)
+}
@@ -0,0 +1,80 @@
+package i18n
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestT(t *testing.T) {
+	en, ru := NewPrinter(English), NewPrinter(Russian)
+	for _, tc := range []struct {
+		p    *Printer
+		msg  string
+		args []any
+		want string
+	}{
+		{en, "Genre", nil, "Genre"},
+		{ru, "Genre", nil, "Жанр"},
+		{en, "top %d", []any{5}, "top 5"},
+		{ru, "top %d", []any{5}, "топ 5"},
+		{ru, "years %d–%d", []any{1990, 2000}, "годы 1990–2000"},
+		// missing translations fall back to English
+		{ru, "no such message %d", []any{3}, "no such message 3"},
+		// without args, verbs are left alone
+		{ru, "100%", nil, "100%"},
+	} {
+		if got := tc.p.T(tc.msg, tc.args...); got != tc.want {
+			t.Errorf("%s T(%q, %v) = %q, want %q", tc.p.Locale(), tc.msg, tc.args, got, tc.want)
+		}
+	}
+	if l := NewPrinter("de").Locale(); l != English {
+		t.Errorf("NewPrinter(de) locale = %s, want the English fallback", l)
+	}
+}
+
+func TestCompactAndMoney(t *testing.T) {
+	en, ru := NewPrinter(English), NewPrinter(Russian)
+	for _, tc := range []struct {
+		v                          float64
+		compact, money             string
+		compactRu, moneyRu, number string
+	}{
+		{0, "0", "$0", "0", "0 $", "0,00"},
+		{3.14159, "3.14", "$3.14", "3,14", "3,14 $", "3,14"},
+		{1234, "1,234", "$1,234", "1 234", "1 234 $", "1 234,00"},
+		{12345, "12.3K", "$12.3K", "12,3 тыс.", "12,3 тыс. $", "12 345,00"},
+		{999_999, "1M", "$1M", "1 млн", "1 млн $", "999 999,00"},
+		{-2_500_000, "-2.5M", "-$2.5M", "-2,5 млн", "-2,5 млн $", "-2 500 000,00"},
+		{1.5e9, "1.5B", "$1.5B", "1,5 млрд", "1,5 млрд $", "1 500 000 000,00"},
+	} {
+		for _, c := range []struct{ name, got, want string }{
+			{"en Compact", en.Compact(tc.v), tc.compact},
+			{"en Money", en.Money(tc.v), tc.money},
+			{"ru Compact", ru.Compact(tc.v), tc.compactRu},
+			{"ru Money", ru.Money(tc.v), tc.moneyRu},
+			{"ru Number", ru.Number(tc.v, 2), tc.number},
+		} {
+			if c.got != c.want {
+				t.Errorf("%s(%g) = %q, want %q", c.name, tc.v, c.got, c.want)
+			}
+		}
+	}
+}
+
+var verbRe = regexp.MustCompile(`%[-+# 0]*[0-9.]*[vTtbcdoOqxXUeEfFgGsp%]`)
+
+// TestCatalogVerbs pins that translations keep the verbs of the English text
+// in the same order, as T formats them with the same arguments.
+func TestCatalogVerbs(t *testing.T) {
+	for l, messages := range catalog {
+		for msg, tr := range messages {
+			if tr == "" {
+				t.Errorf("%s: empty translation of %q", l, msg)
+			}
+			if want, got := verbRe.FindAllString(msg, -1), verbRe.FindAllString(tr, -1); !slices.Equal(got, want) {
+				t.Errorf("%s: %q has verbs %v, want %v as in %q", l, tr, got, want, msg)
+			}
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"dv/db"
+)
+
+const (
+	keywordCloudFile  = "keywords.html"
+	keywordTrendsFile = "keyword_trends.html"
+)
+
+type keywordCloudChart struct{}
+
+type keywordTrendsChart struct{}
+
+func init() {
+	Register(keywordCloudChart{})
+	Register(keywordTrendsChart{})
+}
+
+func (keywordCloudChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "keywords",
+		Description: "Most frequent keywords sized by movie count and colored by average rating",
+		ChartType:   "WordCloud",
+		Source:      "KeywordTrends",
+		Output:      keywordCloudFile,
+	}
+}
+
+func (keywordCloudChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.KeywordCloudWithCount(ctx)
+}
+
+func (keywordTrendsChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "keyword_trends",
+		Description: "Movies per year for the most frequent keywords (rising and falling themes)",
+		ChartType:   "Line",
+		Source:      "KeywordYearlyTrends",
+		Output:      keywordTrendsFile,
+	}
+}
+
+func (keywordTrendsChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.KeywordTrendsWithCount(ctx)
+}
+
+// keywordWord is a word cloud item. go-echarts' WordCloudData has no style,
+// and the extension colors each word from its own textStyle.
+type keywordWord struct {
+	Name      string          `json:"name"`
+	Value     int64           `json:"value"`
+	Rating    float64         `json:"rating"`
+	Revenue   float64         `json:"revenue"`
+	TextStyle *opts.TextStyle `json:"textStyle"`
+}
+
+// keywordScript sets the tooltip, which shows a word's movie count, average
+// rating and revenue.
+const keywordScript = `(function () {
+	var money = %s, number = %s, labels = %s, esc = %s;
+	%%MY_ECHARTS%%.setOption({tooltip: {formatter: function (p) {
+		return '<b>' + esc(p.name) + '</b>' +
+			'<br/>' + esc(labels[0]) + ': ' + number(p.value) +
+			'<br/>' + esc(labels[1]) + ': ' + number(p.data.rating) +
+			'<br/>' + esc(labels[2]) + ': ' + money(p.data.revenue);
+	}}});
+})();`
+
+// KeywordCloudWithCount draws the most frequent keywords, the filter's stop
+// keywords left out, sized by movie count and shaded from the lowest to the
+// highest average rating among them.
+func (c *Charts) KeywordCloudWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.KeywordTrends(ctx, c.filter.KeywordTrendsParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get keyword trends: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no data for keywords")
+	}
+	lo, hi := data[0].AvgRating, data[0].AvgRating
+	for _, d := range data {
+		lo, hi = min(lo, d.AvgRating), max(hi, d.AvgRating)
+	}
+	words := make([]keywordWord, 0, len(data))
+	for _, d := range data {
+		words = append(words, keywordWord{
+			Name:      d.KeywordName.String,
+			Value:     d.MoviesCount,
+			Rating:    d.AvgRating,
+			Revenue:   d.AvgRevenue,
+			TextStyle: &opts.TextStyle{Color: c.theme.Shade(d.AvgRating, lo, hi)},
+		})
+	}
+
+	p := c.printer
+	wc := charts.NewWordCloud()
+	wc.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    p.T("Movie Keywords"),
+			Subtitle: p.T("size: movies; color: avg rating %s–%s", p.Number(lo, 2), p.Number(hi, 2)),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(false)}),
+	)
+	wc.AddSeries(p.T("Keywords"), nil, charts.WithWorldCloudChartOpts(opts.WordCloudChart{
+		Shape:         "circle",
+		SizeRange:     []float32{14, 64},
+		RotationRange: []float32{0, 0},
+	}))
+	wc.MultiSeries[0].Data = words
+	if c.format == FormatSVG {
+		return len(data), c.render(wc, keywordCloudFile)
+	}
+
+	labels, err := json.Marshal([]string{p.T("Movies"), p.T("Avg Rating"), p.T("Avg Revenue")})
+	if err != nil {
+		return 0, err
+	}
+	wc.AddJSFuncs(fmt.Sprintf(keywordScript, p.MoneyFormatterJS(), p.NumberFormatterJS(""), labels, escapeHTMLJS))
+	return len(data), c.render(wc, keywordCloudFile)
+}
+
+// KeywordTrendsWithCount draws a line per frequent keyword over the release
+// years, so themes that rise and fall stand out. Keywords are in order of
+// their total count; years a keyword has no movies count as zero.
+func (c *Charts) KeywordTrendsWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.KeywordYearlyTrends(ctx, c.filter.KeywordYearlyTrendsParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get keyword yearly trends: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no data for keyword trends")
+	}
+	years, keywords, counts := keywordYears(data)
+	labels := make([]string, len(years))
+	for i, y := range years {
+		labels[i] = strconv.Itoa(int(y))
+	}
+
+	p := c.printer
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    p.T("Keyword Trends by Year"),
+			Subtitle: p.T("keywords=%d years=%d (%d-%d)", len(keywords), len(years), years[0], years[len(years)-1]),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true), Type: "scroll", Top: "30"}),
+		charts.WithGridOpts(opts.Grid{Top: "90"}),
+		charts.WithXAxisOpts(opts.XAxis{Name: p.T("Year")}),
+		charts.WithYAxisOpts(opts.YAxis{Name: p.T("Movies")}),
+	)
+	line.SetXAxis(labels)
+	for i, k := range keywords {
+		items := make([]opts.LineData, len(years))
+		for j, n := range counts[i] {
+			items[j] = opts.LineData{Value: n}
+		}
+		line.AddSeries(k, items)
+	}
+	line.SetSeriesOptions(charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(true), ShowSymbol: opts.Bool(false)}))
+	return len(data), c.render(line, keywordTrendsFile)
+}
+
+// keywordYears pivots the yearly rows into one series per keyword over every
+// year any keyword has movies in, ascending. Keywords are ordered by their
+// total count, then by name; years a keyword has no movies count as zero.
+func keywordYears(data []db.KeywordYearlyTrendsRow) (years []int32, keywords []string, counts [][]int64) {
+	seen := make(map[int32]bool)
+	totals := make(map[string]int64)
+	byYear := make(map[string]map[int32]int64)
+	for _, d := range data {
+		if !seen[d.Year] {
+			seen[d.Year] = true
+			years = append(years, d.Year)
+		}
+		name := d.KeywordName.String
+		if byYear[name] == nil {
+			byYear[name] = make(map[int32]int64)
+		}
+		byYear[name][d.Year] += d.MoviesCount
+		totals[name] += d.MoviesCount
+	}
+	slices.Sort(years)
+	keywords = make([]string, 0, len(totals))
+	for k := range totals {
+		keywords = append(keywords, k)
+	}
+	slices.SortFunc(keywords, func(a, b string) int {
+		return cmp.Or(cmp.Compare(totals[b], totals[a]), cmp.Compare(a, b))
+	})
+	counts = make([][]int64, len(keywords))
+	for i, k := range keywords {
+		counts[i] = make([]int64, len(years))
+		for j, y := range years {
+			counts[i][j] = byYear[k][y]
+		}
+	}
+	return years, keywords, counts
+}
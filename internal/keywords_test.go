@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dv/db"
+	"dv/internal/i18n"
+)
+
+func keywordYear(name string, year int32, n int64) db.KeywordYearlyTrendsRow {
+	return db.KeywordYearlyTrendsRow{KeywordName: pgtype.Text{String: name, Valid: true}, Year: year, MoviesCount: n}
+}
+
+func TestKeywordYears(t *testing.T) {
+	years, keywords, counts := keywordYears([]db.KeywordYearlyTrendsRow{
+		keywordYear("sequel", 2001, 2),
+		keywordYear("heist", 1999, 4),
+		keywordYear("sequel", 1999, 3),
+		keywordYear("alien", 2005, 5), // ties with sequel, first by name
+		keywordYear("heist", 2005, 3),
+	})
+	if want := []int32{1999, 2001, 2005}; !slices.Equal(years, want) {
+		t.Errorf("years = %v, want %v", years, want)
+	}
+	if want := []string{"heist", "alien", "sequel"}; !slices.Equal(keywords, want) {
+		t.Errorf("keywords = %v, want %v", keywords, want)
+	}
+	want := [][]int64{{4, 0, 3}, {0, 0, 5}, {3, 2, 0}}
+	if !slices.EqualFunc(counts, want, slices.Equal) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
+
+// keywordsRepo returns its rows for any KeywordTrends call.
+type keywordsRepo struct {
+	*db.Memory
+	rows []db.KeywordTrendsRow
+}
+
+func (r keywordsRepo) KeywordTrends(ctx context.Context, _ db.KeywordTrendsParams) ([]db.KeywordTrendsRow, error) {
+	return r.rows, ctx.Err()
+}
+
+func TestKeywordTooltipEscaping(t *testing.T) {
+	rows := []db.KeywordTrendsRow{{KeywordName: pgtype.Text{String: `<img src=x onerror=alert(1)>`, Valid: true}, MoviesCount: 3, AvgRating: 7, AvgRevenue: 1e6}}
+	dir := t.TempDir() + "/"
+	c := NewCharts(keywordsRepo{rows: rows}, dir, WithLocale(i18n.Russian))
+	if _, err := c.KeywordCloudWithCount(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(dir + keywordCloudFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	var labels []string
+	scriptVar(t, page, "labels", &labels)
+	if !slices.Equal(labels, []string{"Фильмы", "Средний рейтинг", "Средние сборы"}) {
+		t.Errorf("tooltip labels %q, want them translated", labels)
+	}
+	if !strings.Contains(page, "esc(p.name)") {
+		t.Error("the tooltip does not escape the keyword")
+	}
+}
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const languagesFile = "languages.html"
+
+type languagesChart struct{}
+
+func init() { Register(languagesChart{}) }
+
+func (languagesChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "languages",
+		Description: "Original and spoken languages by movie count, colored by revenue or rating",
+		ChartType:   "Treemap",
+		Source:      "LanguagePopularity",
+		Output:      languagesFile,
+	}
+}
+
+func (languagesChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.LanguagesWithCount(ctx)
+}
+
+// languageNode is a treemap node: a language role, or a language within one.
+// go-echarts' TreeMapNode has no style, so leaves carry their color per
+// metric, revenue first, and the shown one as itemStyle.
+type languageNode struct {
+	Name      string          `json:"name"`
+	Value     int64           `json:"value"`
+	Rating    float64         `json:"rating"`
+	Revenue   float64         `json:"revenue"`
+	Colors    []string        `json:"colors,omitempty"`
+	ItemStyle *opts.ItemStyle `json:"itemStyle,omitempty"`
+	Children  []*languageNode `json:"children,omitempty"`
+}
+
+// languagesScript adds the color metric selector above the chart and
+// recolors the languages, and the subtitle, by the selected metric. The
+// tooltip shows a node's movie count, average rating and revenue.
+const languagesScript = `(function () {
+	var chart = %%MY_ECHARTS%%;
+	var roles = %s, metrics = %s, money = %s, number = %s, labels = %s, esc = %s;
+	chart.setOption({tooltip: {formatter: function (p) {
+		return '<b>' + p.treePathInfo.slice(1).map(function (n) { return esc(n.name); }).join(' / ') + '</b>' +
+			'<br/>' + esc(labels[0]) + ': ' + number(p.value) +
+			'<br/>' + esc(labels[1]) + ': ' + number(p.data.rating) +
+			'<br/>' + esc(labels[2]) + ': ' + money(p.data.revenue);
+	}}});
+	function paint(nodes, i) {
+		return nodes.map(function (n) {
+			var m = Object.assign({}, n);
+			if (n.colors) { m.itemStyle = {color: n.colors[i]}; }
+			if (n.children) { m.children = paint(n.children, i); }
+			return m;
+		});
+	}
+	function show(i) {
+		chart.setOption({title: {subtext: metrics[i].subtitle}, series: [{data: paint(roles, i)}]});
+	}
+	var select = document.createElement('select');
+	select.style.margin = '8px';
+	metrics.forEach(function (m, i) {
+		var o = document.createElement('option');
+		o.value = i;
+		o.textContent = m.label;
+		select.appendChild(o);
+	});
+	select.onchange = function () { show(+select.value); };
+	chart.getDom().parentNode.insertBefore(select, chart.getDom());
+	show(0);
+})();`
+
+// LanguagesWithCount draws a treemap of language roles (original, spoken)
+// and their languages, sized by movie count and shaded from the lowest to the
+// highest average revenue among the languages; the page switches the shading
+// to average rating. A role's averages are its languages' weighted by movies.
+func (c *Charts) LanguagesWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.LanguagePopularity(ctx, c.filter.LanguagePopularityParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get language popularity: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no data for languages")
+	}
+	p := c.printer
+	var roles []*languageNode
+	byRole := make(map[string]*languageNode)
+	loRev, hiRev := data[0].AvgRevenue, data[0].AvgRevenue
+	loRating, hiRating := data[0].AvgRating, data[0].AvgRating
+	for _, d := range data {
+		name := p.T("Unknown")
+		if d.LanguageRole.Valid {
+			name = p.T(d.LanguageRole.String)
+		}
+		role := byRole[name]
+		if role == nil {
+			role = &languageNode{Name: name}
+			byRole[name] = role
+			roles = append(roles, role)
+		}
+		role.Value += d.MoviesCount
+		role.Rating += d.AvgRating * float64(d.MoviesCount)
+		role.Revenue += d.AvgRevenue * float64(d.MoviesCount)
+		role.Children = append(role.Children, &languageNode{
+			Name:    d.LanguageName.String,
+			Value:   d.MoviesCount,
+			Rating:  d.AvgRating,
+			Revenue: d.AvgRevenue,
+		})
+		loRev, hiRev = min(loRev, d.AvgRevenue), max(hiRev, d.AvgRevenue)
+		loRating, hiRating = min(loRating, d.AvgRating), max(hiRating, d.AvgRating)
+	}
+	for _, role := range roles {
+		if role.Value > 0 {
+			role.Rating /= float64(role.Value)
+			role.Revenue /= float64(role.Value)
+		}
+		for _, l := range role.Children {
+			l.Colors = []string{c.theme.Shade(l.Revenue, loRev, hiRev), c.theme.Shade(l.Rating, loRating, hiRating)}
+			l.ItemStyle = &opts.ItemStyle{Color: l.Colors[0]}
+		}
+	}
+	metrics := []struct {
+		Label    string `json:"label"`
+		Subtitle string `json:"subtitle"`
+	}{
+		{p.T("Avg Revenue"), p.T("size: movies; color: avg revenue %s–%s", p.Money(loRev), p.Money(hiRev))},
+		{p.T("Avg Rating"), p.T("size: movies; color: avg rating %s–%s", p.Number(loRating, 2), p.Number(hiRating, 2))},
+	}
+
+	tm := charts.NewTreeMap()
+	tm.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: p.T("Movie Languages by Role"), Subtitle: metrics[0].Subtitle}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(false)}),
+		charts.WithInitializationOpts(opts.Initialization{Height: "560px"}),
+	)
+	tm.AddSeries(p.T("Languages"), nil, charts.WithTreeMapOpts(opts.TreeMapChart{
+		Top:        "70",
+		Bottom:     "10",
+		Roam:       opts.Bool(false),
+		UpperLabel: &opts.UpperLabel{Show: opts.Bool(true)},
+		Levels: &[]opts.TreeMapLevel{
+			{ItemStyle: &opts.ItemStyle{BorderColor: c.theme.Background, BorderWidth: 2}, UpperLabel: &opts.UpperLabel{Show: opts.Bool(false)}},
+			{ItemStyle: &opts.ItemStyle{BorderColor: c.theme.Grid, BorderWidth: 4, GapWidth: 1}},
+		},
+	}))
+	tm.MultiSeries[0].Data = roles
+	if c.format == FormatSVG {
+		// the image has no page script, so it keeps the revenue colors
+		return len(data), c.render(tm, languagesFile)
+	}
+
+	var js [3][]byte
+	for i, v := range []any{roles, metrics, []string{p.T("Movies"), p.T("Avg Rating"), p.T("Avg Revenue")}} {
+		if js[i], err = json.Marshal(v); err != nil {
+			return 0, err
+		}
+	}
+	tm.AddJSFuncs(fmt.Sprintf(languagesScript, js[0], js[1], p.MoneyFormatterJS(), p.NumberFormatterJS(""), js[2], escapeHTMLJS))
+	return len(data), c.render(tm, languagesFile)
+}
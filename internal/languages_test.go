@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dv/db"
+	"dv/internal/i18n"
+)
+
+// languagesRepo returns its rows for any LanguagePopularity call.
+type languagesRepo struct {
+	*db.Memory
+	rows []db.LanguagePopularityRow
+}
+
+func (r languagesRepo) LanguagePopularity(ctx context.Context, _ db.LanguagePopularityParams) ([]db.LanguagePopularityRow, error) {
+	return r.rows, ctx.Err()
+}
+
+func language(role, name string, movies int64, rating, revenue float64) db.LanguagePopularityRow {
+	return db.LanguagePopularityRow{
+		LanguageRole: pgtype.Text{String: role, Valid: role != ""},
+		LanguageName: pgtype.Text{String: name, Valid: true},
+		MoviesCount:  movies,
+		AvgRating:    rating,
+		AvgRevenue:   revenue,
+	}
+}
+
+func TestLanguageRoles(t *testing.T) {
+	rows := []db.LanguagePopularityRow{
+		language("Original", "English", 30, 6, 100e6),
+		language("Spoken", "English", 40, 6.5, 90e6),
+		language("Original", "French", 10, 8, 20e6),
+		language("", "Latin", 2, 7, 0),
+		language("Spoken", `<img src=x onerror=alert(1)>`, 1, 5, 1e6),
+	}
+	dir := t.TempDir() + "/"
+	c := NewCharts(languagesRepo{rows: rows}, dir, WithLocale(i18n.Russian))
+	if n, err := c.LanguagesWithCount(context.Background()); err != nil || n != len(rows) {
+		t.Fatalf("LanguagesWithCount = %d, %v; want %d rows", n, err, len(rows))
+	}
+	b, err := os.ReadFile(dir + languagesFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	page := string(b)
+	var roles []languageNode
+	scriptVar(t, page, "roles", &roles)
+
+	// roles in order of appearance, translated, with movie-weighted averages
+	for i, want := range []struct {
+		name            string
+		movies          int64
+		rating, revenue float64
+		languages       int
+	}{
+		{"Язык оригинала", 40, (30*6 + 10*8) / 40.0, (30*100e6 + 10*20e6) / 40, 2},
+		{"Разговорные языки", 41, (40*6.5 + 5) / 41, (40*90e6 + 1e6) / 41, 2},
+		{"Неизвестно", 2, 7, 0, 1},
+	} {
+		if i >= len(roles) {
+			t.Fatalf("%d roles, want %s", len(roles), want.name)
+		}
+		r := roles[i]
+		if r.Name != want.name || r.Value != want.movies || math.Abs(r.Rating-want.rating) > 1e-9 || math.Abs(r.Revenue-want.revenue) > 1e-3 || len(r.Children) != want.languages {
+			t.Errorf("role %d = %s (%d movies, rating %g, revenue %g, %d languages), want %+v", i, r.Name, r.Value, r.Rating, r.Revenue, len(r.Children), want)
+		}
+		for _, l := range r.Children {
+			if len(l.Colors) != 2 || l.ItemStyle == nil || l.ItemStyle.Color != l.Colors[0] {
+				t.Errorf("%s/%s colors %v, want revenue and rating shades with revenue shown", r.Name, l.Name, l.Colors)
+			}
+		}
+	}
+
+	var labels []string
+	scriptVar(t, page, "labels", &labels)
+	if len(labels) != 3 || labels[0] != "Фильмы" {
+		t.Errorf("tooltip labels %q, want them translated", labels)
+	}
+	if !strings.Contains(page, "esc(n.name)") {
+		t.Error("the tooltip does not escape language names")
+	}
+}
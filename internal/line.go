@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const lineFile = "line.html"
+
+type lineChart struct{}
+
+func init() { Register(lineChart{}) }
+
+func (lineChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "line",
+		Description: "Average revenue trend by year (temporal performance)",
+		ChartType:   "Line",
+		Source:      "YearlyTrends",
+		Output:      lineFile,
+	}
+}
+
+func (lineChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.LineChartWithCount(ctx)
+}
+
+func (c *Charts) LineChart(ctx context.Context) error {
+	_, err := c.LineChartWithCount(ctx)
+	return err
+}
+func (c *Charts) LineChartWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.YearlyTrends(ctx, c.filter.YearlyTrendsParams())
+	if err != nil {
+		return 0, err
+	}
+	years := make([]string, 0, len(data))
+	avgRevenue := make([]opts.LineData, 0, len(data))
+	for _, item := range data {
+		years = append(years, fmt.Sprintf("%d", item.Year))
+		avgRevenue = append(avgRevenue, opts.LineData{Value: item.AvgRevenue})
+	}
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: c.printer.T("Average Revenue by Year")}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.printer.T("Year")}),
+		charts.WithYAxisOpts(opts.YAxis{Name: c.printer.T("Average Revenue")}),
+	)
+	line.SetXAxis(years).AddSeries(c.printer.T("Avg Revenue"), avgRevenue).SetSeriesOptions(
+		charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(true)}),
+	)
+	return len(data), c.render(line, lineFile)
+}
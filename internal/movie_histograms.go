@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"dv/db"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// movieHistogram is a histogram of one numeric movie column. Zero values mean
+// "unknown" in the dataset (budget, revenue, runtime...) and are skipped.
+type movieHistogram struct {
+	column string
+	desc   string
+	value  func(db.MovieNumericMetricsRow) (float64, bool)
+	opts   HistogramOptions
+}
+
+var movieHistograms = []movieHistogram{
+	{
+		column: "budget",
+		desc:   "Distribution of movie budgets (log-scaled)",
+		value:  func(r db.MovieNumericMetricsRow) (float64, bool) { return float64(r.Budget.Int32), r.Budget.Valid },
+		opts:   HistogramOptions{Title: "Budget Histogram", Axis: "Budget ($)", LogScale: true, Overlay: OverlayKDE},
+	},
+	{
+		column: "revenue",
+		desc:   "Distribution of movie revenues (log-scaled)",
+		value:  func(r db.MovieNumericMetricsRow) (float64, bool) { return float64(r.Revenue.Int64), r.Revenue.Valid },
+		opts:   HistogramOptions{Title: "Revenue Histogram", Axis: "Revenue ($)", LogScale: true, Overlay: OverlayKDE},
+	},
+	{
+		column: "runtime",
+		desc:   "Distribution of movie runtimes",
+		value:  func(r db.MovieNumericMetricsRow) (float64, bool) { return float64(r.Runtime.Int32), r.Runtime.Valid },
+		opts:   HistogramOptions{Title: "Runtime Histogram", Axis: "Runtime (min)", Unit: " min", ClipLow: 0.005, ClipHigh: 0.995, Overlay: OverlayDensity},
+	},
+	{
+		column: "vote_average",
+		desc:   "Distribution of average user ratings",
+		value:  func(r db.MovieNumericMetricsRow) (float64, bool) { return numericValue(r.VoteAverage) },
+		opts:   HistogramOptions{Title: "Rating Histogram", Axis: "Vote Average", Binning: BinFixedWidth, Width: 0.25, Overlay: OverlayKDE},
+	},
+	{
+		column: "popularity",
+		desc:   "Distribution of TMDB popularity scores (log-scaled)",
+		value:  func(r db.MovieNumericMetricsRow) (float64, bool) { return numericValue(r.Popularity) },
+		opts:   HistogramOptions{Title: "Popularity Histogram", Axis: "Popularity", LogScale: true, Overlay: OverlayDensity},
+	},
+}
+
+func init() {
+	for _, h := range movieHistograms {
+		Register(h)
+	}
+}
+
+func (h movieHistogram) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "hist_" + h.column,
+		Description: h.desc,
+		ChartType:   "Histogram",
+		Source:      "MovieNumericMetrics",
+		Output:      "hist_" + h.column + ".html",
+	}
+}
+
+func (h movieHistogram) Generate(ctx context.Context, c *Charts) (int, error) {
+	data, err := c.repo.MovieNumericMetrics(ctx, c.filter.MovieNumericMetricsParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get movie metrics: %w", err)
+	}
+	values := make([]float64, 0, len(data))
+	for _, r := range data {
+		if v, ok := h.value(r); ok && v > 0 {
+			values = append(values, v)
+		}
+	}
+	return c.HistogramOf(values, h.opts, h.Info().Output)
+}
+
+func numericValue(n pgtype.Numeric) (float64, bool) {
+	f, err := n.Float64Value()
+	if err != nil || !f.Valid {
+		return 0, false
+	}
+	return f.Float64, true
+}
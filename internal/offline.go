@@ -0,0 +1,102 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"dv/internal/assets"
+)
+
+// AssetsMode selects where rendered HTML loads ECharts from.
+type AssetsMode string
+
+const (
+	AssetsCDN    AssetsMode = "cdn"    // go-echarts default host, needs network
+	AssetsInline AssetsMode = "inline" // vendored scripts inlined into every file
+	AssetsLocal  AssetsMode = "local"  // vendored scripts copied once to <dir>/assets/
+)
+
+const localAssetsDir = "assets/"
+
+// echartsAsset is the core bundle every chart page loads.
+const echartsAsset = "echarts.min.js"
+
+// ParseAssetsMode parses an assets mode. The offline modes fail here, not on
+// every chart, when the bundles were never vendored.
+func ParseAssetsMode(s string) (AssetsMode, error) {
+	switch m := AssetsMode(s); m {
+	case AssetsCDN:
+		return m, nil
+	case AssetsInline, AssetsLocal:
+		if !assets.Has(echartsAsset) {
+			return "", fmt.Errorf("assets mode %q needs %s, which this build does not embed (see internal/assets/vendor/README.md)", s, echartsAsset)
+		}
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown assets mode %q (want %q, %q or %q)", s, AssetsCDN, AssetsInline, AssetsLocal)
+}
+
+// WithAssets sets how chart pages reference ECharts and its extensions.
+func WithAssets(m AssetsMode) Option {
+	return func(c *Charts) {
+		c.assets = m
+	}
+}
+
+var assetScriptRe = regexp.MustCompile(`<script src="` + regexp.QuoteMeta(assets.DefaultHost) + `([^"]+)"></script>`)
+
+// localizeAssets rewrites script tags pointing at the go-echarts host to the
+// vendored copies, either inline or under the shared local assets directory.
+func (c *Charts) localizeAssets(html []byte) ([]byte, error) {
+	var firstErr error
+	out := assetScriptRe.ReplaceAllFunc(html, func(tag []byte) []byte {
+		name := string(assetScriptRe.FindSubmatch(tag)[1])
+		body, err := c.readAsset(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return tag
+		}
+		if c.assets == AssetsInline {
+			var b bytes.Buffer
+			b.WriteString("<script>")
+			// a bundle containing "</script>" would end the tag early
+			b.Write(bytes.ReplaceAll(body, []byte("</script"), []byte(`<\/script`)))
+			b.WriteString("</script>")
+			return b.Bytes()
+		}
+		if err := c.writeLocalAsset(name, body); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return tag
+		}
+		return []byte(`<script src="` + template.HTMLEscapeString(localAssetsDir+name) + `"></script>`)
+	})
+	return out, firstErr
+}
+
+func (c *Charts) writeLocalAsset(name string, body []byte) error {
+	c.assetsMu.Lock()
+	defer c.assetsMu.Unlock()
+	if c.writtenAssets[name] {
+		return nil
+	}
+	dst := filepath.Join(c.dir, localAssetsDir, name)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(dst, body, 0o644); err != nil {
+		return err
+	}
+	if c.writtenAssets == nil {
+		c.writtenAssets = make(map[string]bool)
+	}
+	c.writtenAssets[name] = true
+	return nil
+}
@@ -0,0 +1,79 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"dv/db"
+	"dv/internal/assets"
+)
+
+// offlineCharts renders from the fixtures in mode. Without vendored bundles
+// the assets are stubbed, so the rewriting is still checked.
+func offlineCharts(t *testing.T, mode AssetsMode) (*Charts, string) {
+	t.Helper()
+	repo, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir() + "/"
+	c := NewCharts(repo, dir, WithAssets(mode), WithDashboard(false))
+	if !assets.Has(echartsAsset) {
+		c.readAsset = func(name string) ([]byte, error) {
+			return []byte("/* " + name + " */ var echarts = {};"), nil
+		}
+	}
+	return c, dir
+}
+
+func TestInlineAssets(t *testing.T) {
+	c, dir := offlineCharts(t, AssetsInline)
+	if err := c.Generate(context.Background(), "bar", "keywords"); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"bar.html", keywordCloudFile} {
+		html, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(html, []byte(`<script src="http`)) {
+			t.Errorf("%s still loads a script from the network", file)
+		}
+		if !bytes.Contains(html, []byte("echarts")) {
+			t.Errorf("%s has no inlined ECharts", file)
+		}
+	}
+}
+
+func TestLocalAssets(t *testing.T) {
+	c, dir := offlineCharts(t, AssetsLocal)
+	if err := c.Generate(context.Background(), "bar"); err != nil {
+		t.Fatal(err)
+	}
+	html, err := os.ReadFile(filepath.Join(dir, "bar.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(html, []byte(`<script src="assets/echarts.min.js">`)) {
+		t.Error("bar.html does not load the local ECharts copy")
+	}
+	if _, err := os.Stat(filepath.Join(dir, localAssetsDir, echartsAsset)); err != nil {
+		t.Errorf("local ECharts copy not written: %v", err)
+	}
+}
+
+func TestParseAssetsMode(t *testing.T) {
+	if _, err := ParseAssetsMode("cdn"); err != nil {
+		t.Errorf("ParseAssetsMode(cdn) = %v", err)
+	}
+	if _, err := ParseAssetsMode("offline"); err == nil {
+		t.Error("ParseAssetsMode(offline) should fail")
+	}
+	_, err := ParseAssetsMode("inline")
+	if vendored := assets.Has(echartsAsset); vendored != (err == nil) {
+		t.Errorf("ParseAssetsMode(inline) = %v with ECharts vendored=%v", err, vendored)
+	}
+}
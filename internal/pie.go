@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const pieFile = "pie.html"
+
+type pieChart struct{}
+
+func init() { Register(pieChart{}) }
+
+func (pieChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "pie",
+		Description: "Distribution of movies by runtime duration segment (commercial success proxy)",
+		ChartType:   "Pie",
+		Source:      "RuntimeSuccessSegments",
+		Output:      pieFile,
+	}
+}
+
+func (pieChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.PieChartWithCount(ctx)
+}
+
+func (c *Charts) PieChart(ctx context.Context) error { _, err := c.PieChartWithCount(ctx); return err }
+func (c *Charts) PieChartWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.RuntimeSuccessSegments(ctx, c.filter.RuntimeSuccessSegmentsParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get runtime success segments: %w", err)
+	}
+	items := make([]opts.PieData, 0, len(data))
+	for _, d := range data {
+		items = append(items, opts.PieData{Name: c.printer.T(d.DurationCategory), Value: d.MoviesCount})
+	}
+	pie := charts.NewPie()
+	pie.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: c.printer.T("Movie Duration Distribution")}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+	)
+	pie.AddSeries(c.printer.T("Duration Segments"), items).SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Formatter: "{b}: {c} ({d}%)"}),
+	)
+	return len(data), c.render(pie, pieFile)
+}
@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChartInfo describes a registered chart: what it shows, where its data comes
+// from and which file it is rendered to.
+type ChartInfo struct {
+	Name        string
+	Description string
+	ChartType   string
+	Source      string        // sqlc query backing the chart
+	Output      string        // file name relative to the charts dir
+	Disabled    bool          // skipped by GenerateAllCharts unless enabled explicitly
+	Timeout     time.Duration // overrides the service-wide per-chart timeout when set
+	Critical    bool          // failure fails the run under FailOnCritical
+}
+
+// Chart is a self-describing chart. Implementations register themselves from an
+// init function so adding a chart never touches shared code.
+type Chart interface {
+	Info() ChartInfo
+	Generate(ctx context.Context, c *Charts) (int, error) // returns row count
+}
+
+type Registry struct {
+	mu     sync.RWMutex
+	charts map[string]Chart
+	infos  map[string]ChartInfo // effective info, including enable/critical overrides
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		charts: make(map[string]Chart),
+		infos:  make(map[string]ChartInfo),
+	}
+}
+
+var defaultRegistry = NewRegistry()
+
+// DefaultRegistry holds every chart registered through Register. Enable and
+// disable charts on a Clone passed to WithRegistry, never on it directly.
+func DefaultRegistry() *Registry {
+	return defaultRegistry
+}
+
+// Register adds a chart to the default registry and panics on duplicate names.
+func Register(ch Chart) {
+	if err := defaultRegistry.Register(ch); err != nil {
+		panic(err)
+	}
+}
+
+func (r *Registry) Register(ch Chart) error {
+	info := ch.Info()
+	if info.Name == "" {
+		return fmt.Errorf("chart has empty name")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.charts[info.Name]; ok {
+		return fmt.Errorf("chart %q already registered", info.Name)
+	}
+	r.charts[info.Name] = ch
+	r.infos[info.Name] = info
+	return nil
+}
+
+func (r *Registry) Get(name string) (Chart, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ch, ok := r.charts[name]
+	return ch, ok
+}
+
+// Info returns the chart's current info, reflecting Enable/Disable/MarkCritical.
+func (r *Registry) Info(name string) (ChartInfo, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	info, ok := r.infos[name]
+	return info, ok
+}
+
+// List returns info for all registered charts sorted by name.
+func (r *Registry) List() []ChartInfo {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]ChartInfo, 0, len(r.infos))
+	for _, info := range r.infos {
+		out = append(out, info)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Enabled returns info for the enabled charts sorted by name.
+func (r *Registry) Enabled() []ChartInfo {
+	var out []ChartInfo
+	for _, info := range r.List() {
+		if !info.Disabled {
+			out = append(out, info)
+		}
+	}
+	return out
+}
+
+func (r *Registry) Enable(names ...string) error {
+	return r.update(names, func(info *ChartInfo) { info.Disabled = false })
+}
+
+func (r *Registry) Disable(names ...string) error {
+	return r.update(names, func(info *ChartInfo) { info.Disabled = true })
+}
+
+// MarkCritical flags charts whose failure fails the run under FailOnCritical.
+func (r *Registry) MarkCritical(names ...string) error {
+	return r.update(names, func(info *ChartInfo) { info.Critical = true })
+}
+
+func (r *Registry) update(names []string, fn func(*ChartInfo)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		if _, ok := r.infos[name]; !ok {
+			return fmt.Errorf("unknown chart %q", name)
+		}
+	}
+	for _, name := range names {
+		info := r.infos[name]
+		fn(&info)
+		r.infos[name] = info
+	}
+	return nil
+}
+
+// Clone copies the registry so enable/disable changes stay local to one Charts.
+func (r *Registry) Clone() *Registry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cp := NewRegistry()
+	for name, ch := range r.charts {
+		cp.charts[name] = ch
+		cp.infos[name] = r.infos[name]
+	}
+	return cp
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+type ChartStatus string
+
+const (
+	StatusOK      ChartStatus = "OK"
+	StatusFailed  ChartStatus = "FAILED"
+	StatusSkipped ChartStatus = "SKIPPED" // not started, or stopped, because the run was cancelled
+)
+
+type ChartResult struct {
+	Info     ChartInfo
+	Status   ChartStatus
+	Rows     int
+	Duration time.Duration
+	Err      error
+}
+
+// RunReport holds one result per chart in the order the charts were requested.
+type RunReport struct {
+	Results []ChartResult
+	Workers int
+	Elapsed time.Duration
+}
+
+// Err returns a *RunError listing every chart that did not succeed, or nil.
+func (r *RunReport) Err() error {
+	var failed []ChartResult
+	for _, res := range r.Results {
+		if res.Status != StatusOK {
+			failed = append(failed, res)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &RunError{Total: len(r.Results), Failures: failed}
+}
+
+func (r *RunReport) Print(w io.Writer) {
+	ok := 0
+	for _, res := range r.Results {
+		line := fmt.Sprintf("%-9s %-14s %-18s rows=%-5d %8s", "["+string(res.Status)+"]", res.Info.ChartType, res.Info.Name, res.Rows, res.Duration.Round(time.Millisecond))
+		if res.Status == StatusOK {
+			ok++
+			fmt.Fprintf(w, "%s -> %s\n", line, res.Info.Description)
+		} else {
+			fmt.Fprintf(w, "%s !! %v\n", line, res.Err)
+		}
+	}
+	fmt.Fprintf(w, "%d/%d charts generated in %s (workers=%d)\n", ok, len(r.Results), r.Elapsed.Round(time.Millisecond), r.Workers)
+}
+
+// RunError aggregates chart failures of a single run.
+type RunError struct {
+	Total    int
+	Failures []ChartResult
+}
+
+func (e *RunError) Error() string {
+	parts := make([]string, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		parts = append(parts, fmt.Sprintf("%s: %v", f.Info.Name, f.Err))
+	}
+	return fmt.Sprintf("%d of %d charts failed: %s", len(e.Failures), e.Total, strings.Join(parts, "; "))
+}
+
+func (e *RunError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Failures))
+	for _, f := range e.Failures {
+		errs = append(errs, f.Err)
+	}
+	return errs
+}
+
+// Critical reports whether any failed chart is marked critical. Skipped charts
+// do not count: they only show that another chart failed first.
+func (e *RunError) Critical() bool {
+	for _, f := range e.Failures {
+		if f.Info.Critical && f.Status == StatusFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// FailPolicy decides whether a run with failures should be treated as failed.
+type FailPolicy string
+
+const (
+	FailOnAny      FailPolicy = "any"
+	FailOnCritical FailPolicy = "critical"
+)
+
+func ParseFailPolicy(s string) (FailPolicy, error) {
+	switch p := FailPolicy(s); p {
+	case FailOnAny, FailOnCritical:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown fail policy %q (want %q or %q)", s, FailOnAny, FailOnCritical)
+}
+
+// Fails applies the policy to the error returned by GenerateAllCharts.
+// Errors other than *RunError always fail.
+func (p FailPolicy) Fails(err error) bool {
+	if err == nil {
+		return false
+	}
+	var runErr *RunError
+	if p == FailOnCritical && errors.As(err, &runErr) {
+		return runErr.Critical()
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// testChart succeeds or fails at once, or with block set waits for its context
+// (or a moment, when nothing cancels it).
+type testChart struct {
+	name  string
+	err   error
+	block bool
+}
+
+func (ch testChart) Info() ChartInfo { return ChartInfo{Name: ch.name, Output: ch.name + ".html"} }
+
+func (ch testChart) Generate(ctx context.Context, _ *Charts) (int, error) {
+	if ch.block {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(200 * time.Millisecond):
+		}
+	}
+	if ch.err != nil {
+		return 0, ch.err
+	}
+	return 1, nil
+}
+
+func TestFailPolicy(t *testing.T) {
+	broken := errors.New("query failed")
+	for _, tc := range []struct {
+		name    string
+		charts  []testChart
+		marked  []string
+		workers int
+		// Fails under FailOnAny and FailOnCritical, without and with -continue
+		any, critical, critContinue bool
+		statuses                    []ChartStatus // without -continue
+	}{
+		{
+			name:     "all ok",
+			charts:   []testChart{{name: "a"}, {name: "b"}},
+			marked:   []string{"a"},
+			workers:  1,
+			statuses: []ChartStatus{StatusOK, StatusOK},
+		},
+		{
+			name:     "non-critical failure skips a critical chart",
+			charts:   []testChart{{name: "a", err: broken}, {name: "b"}},
+			marked:   []string{"b"},
+			workers:  1,
+			any:      true,
+			statuses: []ChartStatus{StatusFailed, StatusSkipped},
+		},
+		{
+			name:     "non-critical failure cancels a running critical chart",
+			charts:   []testChart{{name: "a", block: true}, {name: "b", err: broken}},
+			marked:   []string{"a"},
+			workers:  2,
+			any:      true,
+			statuses: []ChartStatus{StatusSkipped, StatusFailed},
+		},
+		{
+			name:         "critical failure",
+			charts:       []testChart{{name: "a"}, {name: "b", err: broken}},
+			marked:       []string{"b"},
+			workers:      1,
+			any:          true,
+			critical:     true,
+			critContinue: true,
+			statuses:     []ChartStatus{StatusOK, StatusFailed},
+		},
+		{
+			name:         "critical failure after a non-critical one",
+			charts:       []testChart{{name: "a", err: broken}, {name: "b", err: broken}},
+			marked:       []string{"b"},
+			workers:      1,
+			any:          true,
+			critContinue: true, // without -continue b never runs
+			statuses:     []ChartStatus{StatusFailed, StatusSkipped},
+		},
+	} {
+		for _, keepGoing := range []bool{false, true} {
+			registry := NewRegistry()
+			for _, ch := range tc.charts {
+				if err := registry.Register(ch); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := registry.MarkCritical(tc.marked...); err != nil {
+				t.Fatal(err)
+			}
+			c := NewCharts(nil, t.TempDir()+"/", WithRegistry(registry), WithWorkers(tc.workers),
+				WithContinueOnError(keepGoing), WithDashboard(false))
+			report := c.run(context.Background(), registry.List())
+			err := report.Err()
+
+			wantCritical := tc.critical
+			if keepGoing {
+				wantCritical = tc.critContinue
+			}
+			if got := FailOnAny.Fails(err); got != tc.any {
+				t.Errorf("%s (continue %v): FailOnAny.Fails = %v, want %v", tc.name, keepGoing, got, tc.any)
+			}
+			if got := FailOnCritical.Fails(err); got != wantCritical {
+				t.Errorf("%s (continue %v): FailOnCritical.Fails(%v) = %v, want %v", tc.name, keepGoing, err, got, wantCritical)
+			}
+			if keepGoing {
+				for _, res := range report.Results {
+					if res.Status == StatusSkipped {
+						t.Errorf("%s: %s skipped despite -continue", tc.name, res.Info.Name)
+					}
+				}
+				continue
+			}
+			for i, res := range report.Results {
+				if res.Status != tc.statuses[i] {
+					t.Errorf("%s: %s is %s, want %s", tc.name, res.Info.Name, res.Status, tc.statuses[i])
+				}
+			}
+		}
+	}
+}
+
+func TestFailPolicyOtherErrors(t *testing.T) {
+	err := errors.New("failed to write dashboard")
+	for _, p := range []FailPolicy{FailOnAny, FailOnCritical} {
+		if !p.Fails(err) {
+			t.Errorf("%s.Fails(%v) = false, want errors other than a RunError to fail", p, err)
+		}
+		if p.Fails(nil) {
+			t.Errorf("%s.Fails(nil) = true", p)
+		}
+	}
+	if _, err := ParseFailPolicy("some"); err == nil {
+		t.Error("ParseFailPolicy(\"some\") succeeded")
+	}
+}
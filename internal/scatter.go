@@ -0,0 +1,194 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"dv/db"
+	"dv/pkg/stats"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const scatterFile = "scatter.html"
+
+type scatterChart struct{}
+
+func init() { Register(scatterChart{}) }
+
+func (scatterChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "scatter",
+		Description: "Budget vs Revenue with ROI color (capital efficiency)",
+		ChartType:   "Scatter",
+		Source:      "ListTopProfitableMovies",
+		Output:      scatterFile,
+	}
+}
+
+func (scatterChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.ScatterPlotWithCount(ctx)
+}
+
+func (c *Charts) ScatterPlot(ctx context.Context) error {
+	_, err := c.ScatterPlotWithCount(ctx)
+	return err
+}
+
+// ScatterOptions tunes the budget vs revenue scatter.
+type ScatterOptions struct {
+	LogAxes  bool // log scale on both axes; the trend line is then a power-law fit
+	Outliers int  // label the N movies furthest from the trend line
+}
+
+// WithScatterOptions tunes the scatter; a negative Outliers labels none.
+func WithScatterOptions(o ScatterOptions) Option {
+	return func(c *Charts) {
+		o.Outliers = max(0, o.Outliers)
+		c.scatter = o
+	}
+}
+
+// scatterTooltip shows title, budget, revenue and ROI for movie points and the
+// line name for mark lines.
+const scatterTooltip = `function (p) {
+	if (p.componentType === 'markLine') { return p.name; }
+	var v = p.value;
+	return p.name + '<br/>%s: $' + Number(v[0]).toLocaleString('%s') +
+		'<br/>%s: $' + Number(v[1]).toLocaleString('%s') +
+		'<br/>ROI: ' + Number(v[2]).toLocaleString('%s') + '%%';
+}`
+
+// movieSampleParams selects the movies the scatter and the ROI histogram draw.
+// The filter's TopN ranks groups in the other charts; applied here it would
+// only thin out the sample, so the query keeps its own row limit.
+func (c *Charts) movieSampleParams() db.ListTopProfitableMoviesParams {
+	f := c.filter
+	f.TopN = 0
+	return f.ListTopProfitableMoviesParams()
+}
+
+func (c *Charts) ScatterPlotWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.ListTopProfitableMovies(ctx, c.movieSampleParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get profitable movies: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no data for scatter")
+	}
+	type movie struct {
+		title    string
+		budget   float64
+		revenue  float64
+		roi      float64
+		residual float64
+	}
+	movies := make([]movie, 0, len(data))
+	for _, m := range data {
+		revenue := int64(0)
+		if m.Revenue.Valid {
+			revenue = m.Revenue.Int64
+		}
+		movies = append(movies, movie{title: m.Title.String, budget: float64(m.Budget.Int32), revenue: float64(revenue), roi: m.RoiPercent})
+	}
+
+	// Fit revenue on budget; in log space when the axes are logarithmic so the
+	// fitted line is straight on screen.
+	scale := func(v float64) float64 { return v }
+	unscale := scale
+	if c.scatter.LogAxes {
+		scale, unscale = math.Log10, func(v float64) float64 { return math.Pow(10, v) }
+	}
+	xs := make([]float64, len(movies))
+	ys := make([]float64, len(movies))
+	rois := make([]float64, len(movies))
+	for i, m := range movies {
+		xs[i], ys[i], rois[i] = scale(m.budget), scale(m.revenue), m.roi
+	}
+	fit := stats.LinearRegression(xs, ys)
+	for i := range movies {
+		movies[i].residual = math.Abs(ys[i] - fit.Predict(xs[i]))
+	}
+
+	// the largest residuals get their own labelled series
+	slices.SortFunc(movies, func(a, b movie) int { return cmp.Compare(b.residual, a.residual) })
+	n := min(max(0, c.scatter.Outliers), len(movies))
+	outliers, rest := movies[:n], movies[n:]
+	// Sort points by budget so hover / color continuity improves (optional)
+	slices.SortFunc(rest, func(a, b movie) int { return cmp.Compare(a.budget, b.budget) })
+	toPoints := func(ms []movie) []opts.ScatterData {
+		points := make([]opts.ScatterData, 0, len(ms))
+		for _, m := range ms {
+			points = append(points, opts.ScatterData{Name: m.title, Value: []interface{}{m.budget, m.revenue, m.roi}})
+		}
+		return points
+	}
+
+	// colour scale on ROI, clipped to the 5–95% range so a few extreme
+	// micro-budget hits don't wash out the rest
+	roiSample := stats.NewSample(rois)
+	lo, hi := roiSample.Quantile(0.05), roiSample.Quantile(0.95)
+	minX, maxX := stats.MinMax(xs)
+	axisType := "value"
+	if c.scatter.LogAxes {
+		axisType = "log"
+	}
+
+	scatter := charts.NewScatter()
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    c.printer.T("Budget vs Revenue (Top Profitable Movies)"),
+			Subtitle: c.printer.T("n=%s trend: %s R²=%s", c.printer.Int(int64(len(movies))), c.trendLabel(fit), c.printer.Number(fit.R2, 2)),
+		}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.printer.T("Budget ($)"), Type: axisType}),
+		charts.WithYAxisOpts(opts.YAxis{Name: c.printer.T("Revenue ($)"), Type: axisType}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Formatter: opts.FuncOpts(c.scatterTooltip())}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithVisualMapOpts(opts.VisualMap{
+			Type:       "continuous",
+			Calculable: opts.Bool(true),
+			Min:        float32(lo),
+			Max:        float32(hi),
+			Dimension:  "2",
+			Text:       []string{c.printer.T("ROI %"), ""},
+			Right:      "2%",
+			Top:        "middle",
+			InRange:    &opts.VisualMapInRange{Color: c.theme.Diverging},
+		}),
+	)
+	scatter.AddSeries(c.printer.T("Movies"), toPoints(rest)).SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+		charts.WithMarkLineStyleOpts(opts.MarkLineStyle{Symbol: []string{"none", "none"}, Label: &opts.Label{Show: opts.Bool(true), Formatter: "{b}"}}),
+		charts.WithMarkLineNameCoordItemOpts(
+			opts.MarkLineNameCoordItem{Name: c.printer.T("Break-even"), Coordinate0: []interface{}{unscale(minX), unscale(minX)}, Coordinate1: []interface{}{unscale(maxX), unscale(maxX)}},
+			opts.MarkLineNameCoordItem{Name: c.printer.T("Trend"), Coordinate0: []interface{}{unscale(minX), unscale(fit.Predict(minX))}, Coordinate1: []interface{}{unscale(maxX), unscale(fit.Predict(maxX))}},
+		),
+	)
+	if n > 0 {
+		scatter.AddSeries(c.printer.T("Outliers"), toPoints(outliers)).SetSeriesOptions(
+			charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Position: "right", Formatter: "{b}"}),
+		)
+	}
+	return len(data), c.render(scatter, scatterFile)
+}
+
+func (c *Charts) scatterTooltip() string {
+	tag := string(c.printer.Locale())
+	return fmt.Sprintf(scatterTooltip, c.printer.T("Budget"), tag, c.printer.T("Revenue"), tag, tag)
+}
+
+func (c *Charts) trendLabel(fit stats.LinearFit) string {
+	p := c.printer
+	if c.scatter.LogAxes {
+		return p.T("revenue ≈ %s·budget^%s", p.Compact(math.Pow(10, fit.Intercept)), p.Number(fit.Slope, 2))
+	}
+	sign := "+"
+	if fit.Intercept < 0 {
+		sign = "-"
+	}
+	return p.T("revenue ≈ %s·budget %s %s", p.Number(fit.Slope, 2), sign, p.Money(math.Abs(fit.Intercept)))
+}
@@ -0,0 +1,61 @@
+package internal
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"dv/db"
+)
+
+func TestScatterOutliers(t *testing.T) {
+	repo, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{-1, 0, 3, 1 << 20} {
+		c := NewCharts(repo, t.TempDir()+"/", WithDashboard(false), WithScatterOptions(ScatterOptions{Outliers: n}))
+		if c.scatter.Outliers < 0 {
+			t.Errorf("WithScatterOptions kept %d outliers", c.scatter.Outliers)
+		}
+		// a negative count set past the option must not panic either
+		c.scatter.Outliers = n
+		if err := c.Generate(context.Background(), "scatter"); err != nil {
+			t.Errorf("scatter with %d outliers: %v", n, err)
+		}
+	}
+}
+
+// sampleRepo records the parameters the movie sample is queried with.
+type sampleRepo struct {
+	*db.Memory
+	mu     sync.Mutex
+	params []db.ListTopProfitableMoviesParams
+}
+
+func (r *sampleRepo) ListTopProfitableMovies(ctx context.Context, arg db.ListTopProfitableMoviesParams) ([]db.ListTopProfitableMoviesRow, error) {
+	r.mu.Lock()
+	r.params = append(r.params, arg)
+	r.mu.Unlock()
+	return r.Memory.ListTopProfitableMovies(ctx, arg)
+}
+
+func TestMovieSampleIgnoresTop(t *testing.T) {
+	fixtures, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &sampleRepo{Memory: fixtures}
+	c := NewCharts(repo, t.TempDir()+"/", WithDashboard(false), WithFilter(db.Filter{Genre: "Drama", TopN: 5, MinCount: 2}))
+	if err := c.Generate(context.Background(), "scatter", "roi_hist"); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.params) != 2 {
+		t.Fatalf("movie sample queried %d times, want once per chart", len(repo.params))
+	}
+	for _, p := range repo.params {
+		if p.TopN.Valid || p.Genre.String != "Drama" {
+			t.Errorf("movie sample queried with %+v, want the genre but no row limit", p)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const seasonalityFile = "timeline.html"
+
+// MissingDatePolicy controls how movies without a release date are shown.
+type MissingDatePolicy string
+
+const (
+	MissingDatesExclude MissingDatePolicy = "exclude" // leave them out, note the count in the subtitle
+	MissingDatesUnknown MissingDatePolicy = "unknown" // add an "Unknown" bucket next to the months
+)
+
+func ParseMissingDatePolicy(s string) (MissingDatePolicy, error) {
+	switch p := MissingDatePolicy(s); p {
+	case MissingDatesExclude, MissingDatesUnknown:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown missing-date policy %q (want %q or %q)", s, MissingDatesExclude, MissingDatesUnknown)
+}
+
+// WithMissingDates sets how the seasonality chart treats undated movies.
+func WithMissingDates(p MissingDatePolicy) Option {
+	return func(c *Charts) {
+		c.missingDates = p
+	}
+}
+
+type seasonalityChart struct{}
+
+func init() { Register(seasonalityChart{}) }
+
+func (seasonalityChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "seasonality",
+		Description: "Monthly releases per year with a year slider (release seasonality)",
+		ChartType:   "Timeline",
+		Source:      "MonthlyReleases",
+		Output:      seasonalityFile,
+	}
+}
+
+func (seasonalityChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.SeasonalityChartWithCount(ctx)
+}
+
+var monthNames = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// SeasonalityChartWithCount renders monthly release counts with one timeline
+// frame per year. go-echarts has no timeline component, so the frames are
+// applied on top of the rendered line chart through a setOption call.
+func (c *Charts) SeasonalityChartWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.MonthlyReleases(ctx, c.filter.MonthlyReleasesParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get monthly releases: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no monthly data")
+	}
+	undated, err := c.repo.CountUndatedMovies(ctx, c.filter.CountUndatedMoviesParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count undated movies: %w", err)
+	}
+	showUnknown := c.missingDates == MissingDatesUnknown && undated > 0
+
+	// year -> 12 monthly counts; rows arrive ordered by year, month
+	years := make([]int32, 0)
+	counts := make(map[int32][]interface{})
+	for _, r := range data {
+		vals, ok := counts[r.Year]
+		if !ok {
+			vals = make([]interface{}, 12)
+			for i := range vals {
+				vals[i] = 0
+			}
+			counts[r.Year] = vals
+			years = append(years, r.Year)
+		}
+		if r.Month >= 1 && r.Month <= 12 {
+			vals[r.Month-1] = r.MoviesCount
+		}
+	}
+
+	categories := make([]string, 0, len(monthNames)+1)
+	for _, m := range monthNames {
+		categories = append(categories, c.printer.T(m))
+	}
+	if showUnknown {
+		categories = append(categories, c.printer.T("Unknown"))
+	}
+
+	labels := make([]string, 0, len(years)+1)
+	frames := make([]map[string]interface{}, 0, len(years)+1)
+	for _, y := range years {
+		vals := counts[y]
+		if showUnknown {
+			vals = append(vals, nil)
+		}
+		labels = append(labels, fmt.Sprint(y))
+		frames = append(frames, seasonalityFrame(c.printer.T("Monthly Releases %d", y), vals))
+	}
+	subtitle := c.printer.T("years=%d (%d-%d)", len(years), years[0], years[len(years)-1])
+	switch {
+	case showUnknown:
+		vals := make([]interface{}, len(categories))
+		vals[len(vals)-1] = undated
+		labels = append(labels, c.printer.T("Unknown"))
+		frames = append(frames, seasonalityFrame(c.printer.T("Movies without Release Date"), vals))
+		subtitle += c.printer.T(" undated=%s shown as Unknown", c.printer.Int(undated))
+	case undated > 0:
+		subtitle += c.printer.T(" undated=%s excluded", c.printer.Int(undated))
+	}
+
+	timeline, err := json.Marshal(map[string]interface{}{
+		"baseOption": map[string]interface{}{
+			"timeline": map[string]interface{}{
+				"axisType":     "category",
+				"data":         labels,
+				"autoPlay":     false,
+				"playInterval": 800,
+				"left":         "5%",
+				"right":        "5%",
+				"bottom":       0,
+			},
+			"grid": map[string]interface{}{"bottom": 90},
+		},
+		"options": frames,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// first frame doubles as the static series shown before the timeline applies
+	first := make([]opts.LineData, 0, len(categories))
+	for _, v := range counts[years[0]] {
+		first = append(first, opts.LineData{Value: v})
+	}
+	line := charts.NewLine()
+	line.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: c.printer.T("Monthly Releases"), Subtitle: subtitle}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.printer.T("Month"), Type: "category"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: c.printer.T("Movies")}),
+		charts.WithInitializationOpts(opts.Initialization{Height: "560px"}),
+	)
+	line.SetXAxis(categories).AddSeries(c.printer.T("Movies"), first).SetSeriesOptions(
+		charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(true)}),
+		charts.WithAreaStyleOpts(opts.AreaStyle{Opacity: opts.Float(0.18)}),
+	)
+	line.AddJSFuncs(fmt.Sprintf("%%MY_ECHARTS%%.setOption(%s);", timeline))
+	return len(data), c.render(line, seasonalityFile)
+}
+
+func seasonalityFrame(title string, vals []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"title":  map[string]interface{}{"text": title},
+		"series": []map[string]interface{}{{"data": vals}},
+	}
+}
@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"dv/db"
+)
+
+// undatedRepo reports n movies without a release date.
+type undatedRepo struct {
+	*db.Memory
+	n int64
+}
+
+func (r undatedRepo) CountUndatedMovies(ctx context.Context, _ db.CountUndatedMoviesParams) (int64, error) {
+	return r.n, ctx.Err()
+}
+
+func TestSeasonalityUndated(t *testing.T) {
+	fixtures, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		policy  MissingDatePolicy
+		undated int64
+		want    []string
+		not     []string
+	}{
+		{MissingDatesExclude, 7, []string{"undated=7 excluded"}, []string{`"Unknown"`, "Movies without Release Date"}},
+		{MissingDatesUnknown, 7, []string{"undated=7 shown as Unknown", `"Unknown"`, "Movies without Release Date"}, []string{"excluded"}},
+		// with nothing undated, neither policy changes the chart
+		{MissingDatesExclude, 0, nil, []string{"undated=", `"Unknown"`}},
+		{MissingDatesUnknown, 0, nil, []string{"undated=", `"Unknown"`}},
+	} {
+		dir := t.TempDir() + "/"
+		c := NewCharts(undatedRepo{fixtures, tc.undated}, dir, WithMissingDates(tc.policy))
+		if _, err := c.SeasonalityChartWithCount(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		b, err := os.ReadFile(dir + seasonalityFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		page := string(b)
+		for _, want := range tc.want {
+			if !strings.Contains(page, want) {
+				t.Errorf("%s with %d undated: page lacks %q", tc.policy, tc.undated, want)
+			}
+		}
+		for _, not := range tc.not {
+			if strings.Contains(page, not) {
+				t.Errorf("%s with %d undated: page has %q", tc.policy, tc.undated, not)
+			}
+		}
+	}
+
+	if _, err := ParseMissingDatePolicy("drop"); err == nil {
+		t.Error("ParseMissingDatePolicy(drop) succeeded, want an error")
+	}
+}
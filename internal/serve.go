@@ -0,0 +1,260 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"dv/internal/assets"
+	"dv/pkg/logger"
+)
+
+// Server serves every registered chart at /<output file>, regenerating it from
+// the database once the cached copy is older than the TTL. If regenerating
+// fails, the last good copy is served until a later attempt succeeds. The
+// dashboard is served at / and the index at /index.html; both refresh stale
+// charts first. Appending ?refresh=1 to any URL bypasses the cache for
+// requests the server's authorizer admits.
+type Server struct {
+	charts    *Charts
+	ttl       time.Duration
+	authorize func(*http.Request) bool // may bypass the cache
+	files     map[string]string        // output file -> chart name
+	mux       *http.ServeMux
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry holds the last rendering of one chart. Its lock is held while the
+// chart regenerates, so concurrent requests for it share a single query.
+type cacheEntry struct {
+	mu          sync.Mutex
+	chart       servedChart
+	invalidated bool // regenerate on the next request despite the TTL
+}
+
+type servedChart struct {
+	result    ChartResult
+	body      []byte
+	generated time.Time
+}
+
+// ServerOption configures a Server.
+type ServerOption func(*Server)
+
+// WithRefreshAuth sets which requests may force a regeneration with ?refresh;
+// the others get 403. By default only loopback clients may.
+func WithRefreshAuth(authorize func(*http.Request) bool) ServerOption {
+	return func(s *Server) {
+		s.authorize = authorize
+	}
+}
+
+// TokenOrLoopback admits requests carrying "Authorization: Bearer <token>" or,
+// when token is empty, requests from a loopback address, which includes every
+// client behind a local proxy.
+func TokenOrLoopback(token string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		if token != "" {
+			got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+		}
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+}
+
+// NewServer serves the charts of c, which should have a directory of its own:
+// charts are rendered there before being cached in memory. A ttl of 0
+// regenerates a chart on every request.
+func NewServer(c *Charts, ttl time.Duration, opts ...ServerOption) *Server {
+	s := &Server{
+		charts:    c,
+		ttl:       ttl,
+		authorize: TokenOrLoopback(""),
+		files:     make(map[string]string),
+		mux:       http.NewServeMux(),
+		entries:   make(map[string]*cacheEntry),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	for _, info := range c.registry.List() {
+		s.files[c.outputName(info.Output)] = info.Name
+	}
+	s.mux.HandleFunc("GET /{$}", s.handlePage(dashboardFile))
+	s.mux.HandleFunc("GET /"+dashboardFile, s.handlePage(dashboardFile))
+	s.mux.HandleFunc("GET /"+indexFile, s.handlePage(indexFile))
+	s.mux.HandleFunc("GET /"+localAssetsDir+"{name...}", s.handleAsset)
+	s.mux.HandleFunc("GET /{file}", s.handleChart)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) handleChart(w http.ResponseWriter, r *http.Request) {
+	name, ok := s.files[r.PathValue("file")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	info, _ := s.charts.registry.Info(name)
+	force, ok := s.forceRefresh(w, r)
+	if !ok {
+		return
+	}
+	ctx := logger.WithSessionId(r.Context(), newRunID())
+	chart := s.chart(ctx, info, force)
+	if err := chart.result.Err; err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, context.DeadlineExceeded) {
+			status = http.StatusGatewayTimeout
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeContent(w, r, r.PathValue("file"), chart.generated, bytes.NewReader(chart.body))
+}
+
+// handlePage renders the dashboard or index for the enabled charts.
+func (s *Server) handlePage(page string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		force, ok := s.forceRefresh(w, r)
+		if !ok {
+			return
+		}
+		ctx := logger.WithSessionId(r.Context(), newRunID())
+		report := s.refresh(ctx, s.charts.registry.Enabled(), force)
+		var buf bytes.Buffer
+		if err := s.charts.executeTemplate(&buf, page, s.charts.dashboardView(report)); err != nil {
+			slog.ErrorContext(ctx, "failed to render page", slog.String("page", page), slog.String("error", err.Error()))
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Write(buf.Bytes())
+	}
+}
+
+// forceRefresh reports whether r asks to bypass the cache. An unauthorized
+// ?refresh is answered with 403 and ok false.
+func (s *Server) forceRefresh(w http.ResponseWriter, r *http.Request) (force, ok bool) {
+	if !r.URL.Query().Has("refresh") {
+		return false, true
+	}
+	if !s.authorize(r) {
+		http.Error(w, "refresh is not allowed for this client", http.StatusForbidden)
+		return false, false
+	}
+	return true, true
+}
+
+// handleAsset serves the vendored ECharts scripts referenced by pages rendered
+// with AssetsLocal.
+func (s *Server) handleAsset(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	body, err := assets.Read(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "max-age=86400")
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(body))
+}
+
+// refresh brings the listed charts up to date on up to c.workers goroutines and
+// reports them in the order given.
+func (s *Server) refresh(ctx context.Context, list []ChartInfo, force bool) *RunReport {
+	start := time.Now()
+	results := make([]ChartResult, len(list))
+	var g errgroup.Group
+	g.SetLimit(s.charts.workers)
+	for i, info := range list {
+		g.Go(func() error {
+			results[i] = s.chart(ctx, info, force).result
+			return nil
+		})
+	}
+	g.Wait()
+	return &RunReport{Results: results, Workers: s.charts.workers, Elapsed: time.Since(start)}
+}
+
+// chart returns the cached rendering of info, regenerating it when it is stale
+// or force is set.
+func (s *Server) chart(ctx context.Context, info ChartInfo, force bool) servedChart {
+	e := s.entry(info.Name)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if !force && !e.invalidated && e.chart.result.Status == StatusOK && time.Since(e.chart.generated) < s.ttl {
+		return e.chart
+	}
+
+	res := s.charts.generate(ctx, info)
+	var body []byte
+	if res.Status == StatusOK {
+		var err error
+		if body, err = os.ReadFile(s.charts.dir + s.charts.outputName(info.Output)); err != nil {
+			res.Status, res.Err = StatusFailed, err
+		}
+	}
+	if res.Status != StatusOK {
+		if e.chart.body == nil {
+			return servedChart{result: res, generated: time.Now()}
+		}
+		// serve the last good copy; it stays stale, so the next request retries
+		slog.WarnContext(ctx, "serving stale chart",
+			slog.String("chart", info.Name),
+			slog.Time("generated", e.chart.generated),
+			slog.String("error", res.Err.Error()))
+		return e.chart
+	}
+	e.chart = servedChart{result: res, body: body, generated: time.Now()}
+	e.invalidated = false
+	return e.chart
+}
+
+// Invalidate marks every cached chart stale so the next request regenerates
+// it; a chart being rendered is marked once it is done.
+func (s *Server) Invalidate() {
+	s.mu.Lock()
+	entries := make([]*cacheEntry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+	for _, e := range entries {
+		e.mu.Lock()
+		e.invalidated = true
+		e.mu.Unlock()
+	}
+}
+
+func (s *Server) entry(name string) *cacheEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.entries[name]
+	if !ok {
+		e = &cacheEntry{}
+		s.entries[name] = e
+	}
+	return e
+}
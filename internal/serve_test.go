@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"dv/db"
+)
+
+// flakyRepo fails the scatter's query while fail is set.
+type flakyRepo struct {
+	*db.Memory
+	fail  atomic.Bool
+	calls atomic.Int32
+}
+
+func (r *flakyRepo) ListTopProfitableMovies(ctx context.Context, arg db.ListTopProfitableMoviesParams) ([]db.ListTopProfitableMoviesRow, error) {
+	r.calls.Add(1)
+	if r.fail.Load() {
+		return nil, errors.New("connection refused")
+	}
+	return r.Memory.ListTopProfitableMovies(ctx, arg)
+}
+
+func TestServerServesStaleOnError(t *testing.T) {
+	fixtures, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := &flakyRepo{Memory: fixtures}
+	server := NewServer(NewCharts(repo, t.TempDir()+"/", WithDashboard(false)), time.Hour)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/"+scatterFile+query, nil)
+		r.RemoteAddr = "127.0.0.1:5000"
+		server.ServeHTTP(w, r)
+		return w
+	}
+
+	repo.fail.Store(true)
+	if w := get(""); w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d with nothing cached, want 500", w.Code)
+	}
+	repo.fail.Store(false)
+	fresh := get("")
+	if fresh.Code != http.StatusOK || fresh.Body.Len() == 0 {
+		t.Fatalf("status %d, want the chart", fresh.Code)
+	}
+
+	repo.fail.Store(true)
+	for _, query := range []string{"?refresh=1", ""} {
+		before := repo.calls.Load()
+		server.Invalidate()
+		w := get(query)
+		if w.Code != http.StatusOK || w.Body.String() != fresh.Body.String() {
+			t.Errorf("GET %s while failing = %d, want the cached copy", query, w.Code)
+		}
+		if repo.calls.Load() == before {
+			t.Errorf("GET %s served the cache without trying to regenerate", query)
+		}
+	}
+
+	// a stale copy is retried on every request until one succeeds
+	before := repo.calls.Load()
+	get("")
+	repo.fail.Store(false)
+	get("")
+	get("")
+	if n := repo.calls.Load() - before; n != 2 {
+		t.Errorf("%d queries for three requests, want a retry and then the cache", n)
+	}
+}
+
+func TestServerRefreshAuth(t *testing.T) {
+	fixtures, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []struct {
+		name, token, remote, auth, path string
+		want                            int
+	}{
+		{"cached chart from anywhere", "", "203.0.113.7:5000", "", "/" + scatterFile, http.StatusOK},
+		{"loopback refresh", "", "127.0.0.1:5000", "", "/" + scatterFile + "?refresh=1", http.StatusOK},
+		{"remote chart refresh", "", "203.0.113.7:5000", "", "/" + scatterFile + "?refresh=1", http.StatusForbidden},
+		{"remote dashboard refresh", "", "203.0.113.7:5000", "", "/?refresh=1", http.StatusForbidden},
+		{"remote index refresh", "", "203.0.113.7:5000", "", "/" + indexFile + "?refresh", http.StatusForbidden},
+		{"token", "s3cret", "203.0.113.7:5000", "Bearer s3cret", "/?refresh=1", http.StatusOK},
+		{"wrong token", "s3cret", "127.0.0.1:5000", "Bearer guess", "/" + scatterFile + "?refresh=1", http.StatusForbidden},
+	} {
+		repo := &flakyRepo{Memory: fixtures}
+		c := NewCharts(repo, t.TempDir()+"/", WithDashboard(false), WithRegistry(onlyScatter(t)))
+		server := NewServer(c, time.Hour, WithRefreshAuth(TokenOrLoopback(tc.token)))
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		r.RemoteAddr = tc.remote
+		if tc.auth != "" {
+			r.Header.Set("Authorization", tc.auth)
+		}
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, r)
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.want)
+		}
+		if w.Code == http.StatusForbidden && repo.calls.Load() != 0 {
+			t.Errorf("%s: a refused refresh queried the database", tc.name)
+		}
+	}
+}
+
+// onlyScatter is the default registry with every chart but the scatter disabled.
+func onlyScatter(t *testing.T) *Registry {
+	r := DefaultRegistry().Clone()
+	for _, info := range r.List() {
+		if info.Name != "scatter" {
+			if err := r.Disable(info.Name); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return r
+}
@@ -0,0 +1,361 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"dv/db"
+)
+
+// Spec declares a chart in YAML or JSON: where its rows come from (a sqlc query
+// by name or inline SQL), which columns map to the axes and how it is titled.
+// See specs/README.md for the format.
+type Spec struct {
+	Name        string     `yaml:"name"`
+	Title       string     `yaml:"title"`
+	Subtitle    string     `yaml:"subtitle"`
+	Description string     `yaml:"description"`
+	Type        SpecType   `yaml:"type"`
+	Query       string     `yaml:"query"`
+	SQL         string     `yaml:"sql"`
+	Params      []any      `yaml:"params"`
+	X           string     `yaml:"x"`
+	Y           StringList `yaml:"y"`
+	Series      string     `yaml:"series"` // long-format rows: one series per distinct value
+	Color       string     `yaml:"color"`  // scatter: continuous color scale
+	Label       string     `yaml:"label"`  // scatter: point names
+	Sort        *SpecSort  `yaml:"sort"`
+	Limit       int        `yaml:"limit"`
+	XAxis       SpecAxis   `yaml:"x_axis"`
+	YAxis       SpecAxis   `yaml:"y_axis"`
+	Output      string     `yaml:"output"`
+	Disabled    bool       `yaml:"disabled"`
+
+	file  string
+	lines map[string]int // field path -> line, for error messages
+}
+
+type SpecType string
+
+const (
+	SpecBar     SpecType = "bar"
+	SpecHBar    SpecType = "hbar"
+	SpecLine    SpecType = "line"
+	SpecPie     SpecType = "pie"
+	SpecScatter SpecType = "scatter"
+)
+
+type SpecSort struct {
+	Field string `yaml:"field"`
+	Order string `yaml:"order"` // asc (default) or desc
+}
+
+type SpecAxis struct {
+	Name   string     `yaml:"name"`
+	Format AxisFormat `yaml:"format"`
+	Log    bool       `yaml:"log"`
+}
+
+// AxisFormat selects how value axis labels are written.
+type AxisFormat string
+
+const (
+	FormatCompact AxisFormat = "compact" // 12.5M (default)
+	FormatNumber  AxisFormat = "number"  // 12,500,000
+	FormatMoney   AxisFormat = "money"   // $12.5M
+	FormatPercent AxisFormat = "percent" // 12.5%
+)
+
+// StringList accepts a single string or a list of strings.
+type StringList []string
+
+func (l *StringList) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*l = StringList{n.Value}
+		return nil
+	}
+	var list []string
+	if err := n.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// SpecError points at the spec field that failed validation.
+type SpecError struct {
+	File  string
+	Line  int
+	Field string
+	Msg   string
+}
+
+func (e *SpecError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s: %s", e.File, e.Line, e.Field, e.Msg)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.File, e.Field, e.Msg)
+}
+
+func (s *Spec) errorf(field, format string, args ...any) *SpecError {
+	line := s.lines[field]
+	if line == 0 {
+		// fall back to the enclosing field, e.g. "sort" for "sort.order"
+		if i := strings.LastIndexAny(field, ".["); i > 0 {
+			line = s.lines[field[:i]]
+		}
+	}
+	return &SpecError{File: s.file, Line: line, Field: field, Msg: fmt.Sprintf(format, args...)}
+}
+
+var specNameRe = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// LoadSpecs parses every .yaml, .yml and .json file in dir. All invalid specs
+// are reported together.
+func LoadSpecs(dir string) ([]*Spec, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var specs []*Spec
+	var errs []error
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if e.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		data, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s, err := ParseSpec(path, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		specs = append(specs, s)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	seen := make(map[string]string)
+	for _, s := range specs {
+		if prev, ok := seen[s.Name]; ok {
+			errs = append(errs, s.errorf("name", "%q is also defined in %s", s.Name, prev))
+		}
+		seen[s.Name] = s.file
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return specs, nil
+}
+
+// ParseSpec decodes and validates one spec. file names the spec in errors and
+// provides the default chart name.
+func ParseSpec(file string, data []byte) (*Spec, error) {
+	s := &Spec{file: file, lines: make(map[string]int)}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(s); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err == nil && len(root.Content) > 0 {
+		recordLines(root.Content[0], "", s.lines)
+	}
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	if s.Output == "" {
+		s.Output = s.Name + ".html"
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NewSQLSpec starts a spec for an ad-hoc query; source names it in errors.
+func NewSQLSpec(source, sql string) *Spec {
+	return &Spec{SQL: sql, file: source}
+}
+
+// recordLines maps field paths such as "sort.order" or "y[1]" to the line of
+// their key (or list item).
+func recordLines(n *yaml.Node, prefix string, lines map[string]int) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			path := n.Content[i].Value
+			if prefix != "" {
+				path = prefix + "." + path
+			}
+			lines[path] = n.Content[i].Line
+			recordLines(n.Content[i+1], path, lines)
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			path := prefix + "[" + strconv.Itoa(i) + "]"
+			lines[path] = item.Line
+			recordLines(item, path, lines)
+		}
+	}
+}
+
+// Validate checks the spec on its own; column names are checked once the query
+// has run.
+func (s *Spec) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, s.errorf(field, format, args...))
+	}
+
+	if !specNameRe.MatchString(s.Name) {
+		fail("name", "%q must be lowercase letters, digits and underscores", s.Name)
+	}
+	switch s.Type {
+	case SpecBar, SpecHBar, SpecLine, SpecPie, SpecScatter:
+	case "":
+		fail("type", "is required (bar, hbar, line, pie or scatter)")
+	default:
+		fail("type", "unknown chart type %q (want bar, hbar, line, pie or scatter)", s.Type)
+	}
+
+	switch {
+	case s.Query == "" && s.SQL == "":
+		fail("query", "either query or sql is required")
+	case s.Query != "" && s.SQL != "":
+		fail("sql", "query and sql are mutually exclusive")
+	case s.Query != "" && len(s.Params) > 0:
+		fail("params", "only apply to sql; named queries take the run's filter")
+	case s.Query != "":
+		if _, _, ok := db.NamedQuery(s.Query, db.Filter{}); !ok {
+			fail("query", "unknown query %q (want one of %s)", s.Query, strings.Join(db.NamedQueryNames(), ", "))
+		}
+	}
+
+	if s.X == "" {
+		fail("x", "is required")
+	}
+	switch {
+	case len(s.Y) == 0:
+		fail("y", "at least one column is required")
+	case (s.Type == SpecPie || s.Type == SpecScatter) && len(s.Y) > 1:
+		fail("y[1]", "%s charts take a single y column", s.Type)
+	case s.Series != "" && len(s.Y) > 1:
+		fail("y[1]", "a single y column is allowed together with series")
+	}
+	for i, y := range s.Y {
+		if y == "" {
+			fail(fmt.Sprintf("y[%d]", i), "column name is empty")
+		}
+	}
+	if s.Series != "" && (s.Type == SpecPie || s.Type == SpecScatter) {
+		fail("series", "is not supported for %s charts", s.Type)
+	}
+	if s.Color != "" && s.Type != SpecScatter {
+		fail("color", "is only supported for scatter charts")
+	}
+	if s.Label != "" && s.Type != SpecScatter {
+		fail("label", "is only supported for scatter charts")
+	}
+
+	if s.Sort != nil {
+		if s.Sort.Field == "" {
+			fail("sort.field", "is required")
+		}
+		switch strings.ToLower(s.Sort.Order) {
+		case "", "asc", "desc":
+		default:
+			fail("sort.order", "%q must be asc or desc", s.Sort.Order)
+		}
+	}
+	if s.Limit < 0 {
+		fail("limit", "must not be negative")
+	}
+	for _, a := range []struct {
+		field string
+		axis  SpecAxis
+	}{{"x_axis", s.XAxis}, {"y_axis", s.YAxis}} {
+		switch a.axis.Format {
+		case "", FormatCompact, FormatNumber, FormatMoney, FormatPercent:
+		default:
+			fail(a.field+".format", "unknown format %q (want compact, number, money or percent)", a.axis.Format)
+		}
+	}
+	if s.XAxis.Log && s.Type != SpecScatter {
+		fail("x_axis.log", "only scatter charts have a value x axis")
+	}
+	if s.Type == SpecPie && (s.XAxis != SpecAxis{} || s.YAxis != SpecAxis{}) {
+		fail("x_axis", "pie charts have no axes")
+	}
+	if filepath.Base(s.Output) != s.Output || filepath.Ext(s.Output) != ".html" {
+		fail("output", "%q must be a plain .html file name", s.Output)
+	}
+
+	// report in file order
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].(*SpecError).Line < errs[j].(*SpecError).Line
+	})
+	return errors.Join(errs...)
+}
+
+// specColumn is a result column read by the spec and the field naming it.
+type specColumn struct{ field, name string }
+
+// columns returns every column the spec reads, in field order.
+func (s *Spec) columns() []specColumn {
+	cols := []specColumn{{"x", s.X}}
+	for i, y := range s.Y {
+		cols = append(cols, specColumn{fmt.Sprintf("y[%d]", i), y})
+	}
+	for _, c := range []specColumn{{"series", s.Series}, {"color", s.Color}, {"label", s.Label}} {
+		if c.name != "" {
+			cols = append(cols, c)
+		}
+	}
+	if s.Sort != nil {
+		cols = append(cols, specColumn{"sort.field", s.Sort.Field})
+	}
+	return cols
+}
+
+// RegisterSpecs adds a chart per spec to r. A spec may not take the name or
+// output file of a registered chart, nor the dashboard's or the index's file.
+func (r *Registry) RegisterSpecs(specs ...*Spec) error {
+	outputs := map[string]string{dashboardFile: "the dashboard", indexFile: "the index"}
+	for _, info := range r.List() {
+		outputs[info.Output] = fmt.Sprintf("chart %q", info.Name)
+	}
+	var errs []error
+	for _, s := range specs {
+		if _, ok := r.Get(s.Name); ok {
+			errs = append(errs, s.errorf("name", "chart %q already registered", s.Name))
+			continue
+		}
+		if owner, ok := outputs[s.Output]; ok {
+			errs = append(errs, s.errorf("output", "%q is already written by %s", s.Output, owner))
+			continue
+		}
+		if err := r.Register(specChart{s}); err != nil {
+			errs = append(errs, s.errorf("name", "%v", err))
+			continue
+		}
+		outputs[s.Output] = fmt.Sprintf("chart %q", s.Name)
+	}
+	return errors.Join(errs...)
+}
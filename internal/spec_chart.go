@@ -0,0 +1,360 @@
+package internal
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"dv/db"
+)
+
+type specChart struct{ spec *Spec }
+
+func (sc specChart) Info() ChartInfo {
+	s := sc.spec
+	source := s.Query
+	if source == "" {
+		source = "SQL " + s.file
+	}
+	return ChartInfo{
+		Name:        s.Name,
+		Description: s.Description,
+		ChartType:   "Spec/" + string(s.Type),
+		Source:      source,
+		Output:      s.Output,
+		Disabled:    s.Disabled,
+	}
+}
+
+func (sc specChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.SpecChartWithCount(ctx, sc.spec)
+}
+
+// SpecChartWithCount runs the spec's query and renders it.
+func (c *Charts) SpecChartWithCount(ctx context.Context, s *Spec) (int, error) {
+	query, args := s.SQL, s.Params
+	if s.Query != "" {
+		query, args, _ = db.NamedQuery(s.Query, c.filter)
+	}
+	table, err := c.repo.QueryTable(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to run spec %s: %w", s.Name, err)
+	}
+	return c.RenderSpec(s, table)
+}
+
+// RenderSpec renders already queried rows and returns how many were charted.
+func (c *Charts) RenderSpec(s *Spec, table *db.Table) (int, error) {
+	col := make(map[string]int)
+	for _, sc := range s.columns() {
+		i := table.Column(sc.name)
+		if i < 0 {
+			return 0, s.errorf(sc.field, "column %q not in result (have %s)", sc.name, strings.Join(table.ColumnNames(), ", "))
+		}
+		col[sc.field] = i
+	}
+
+	rows := table.Rows
+	if s.Sort != nil {
+		i, desc := col["sort.field"], strings.EqualFold(s.Sort.Order, "desc")
+		rows = slices.Clone(rows)
+		slices.SortStableFunc(rows, func(a, b []any) int {
+			r := compareCells(a[i], b[i])
+			if desc {
+				r = -r
+			}
+			return r
+		})
+	}
+	if s.Limit > 0 && len(rows) > s.Limit {
+		rows = rows[:s.Limit]
+	}
+
+	var chart ChartRenderer
+	switch s.Type {
+	case SpecPie:
+		chart = c.specPie(s, rows, col)
+	case SpecScatter:
+		chart = c.specScatter(s, rows, col)
+	default:
+		chart = c.specCartesian(s, rows, col)
+	}
+	return len(rows), c.render(chart, s.Output)
+}
+
+func (c *Charts) specTitle(s *Spec) opts.Title {
+	title := s.Title
+	if title == "" {
+		title = s.Name
+	}
+	return opts.Title{Title: c.printer.T(title), Subtitle: c.printer.T(s.Subtitle)}
+}
+
+// specAxisName defaults to the column name when the spec sets none.
+func (c *Charts) specAxisName(a SpecAxis, column string) string {
+	if a.Name != "" {
+		return c.printer.T(a.Name)
+	}
+	return column
+}
+
+// axisLabel returns the label options for a value axis in format f; nil keeps
+// the locale's compact default.
+func (c *Charts) axisLabel(f AxisFormat) *opts.AxisLabel {
+	var fn string
+	switch f {
+	case FormatNumber:
+		fn = c.printer.NumberFormatterJS("")
+	case FormatMoney:
+		fn = c.printer.MoneyFormatterJS()
+	case FormatPercent:
+		fn = c.printer.NumberFormatterJS("%")
+	default:
+		return nil
+	}
+	return &opts.AxisLabel{Formatter: opts.FuncOpts(fn)}
+}
+
+func valueAxisType(a SpecAxis) string {
+	if a.Log {
+		return "log"
+	}
+	return "value"
+}
+
+// specCartesian draws bar, hbar and line specs. With a series column the rows
+// are pivoted: one series per distinct series value, categories in first-seen
+// order.
+func (c *Charts) specCartesian(s *Spec, rows [][]any, col map[string]int) ChartRenderer {
+	var categories []string
+	catIndex := make(map[string]int)
+	for _, r := range rows {
+		x := cellString(r[col["x"]])
+		if _, ok := catIndex[x]; !ok {
+			catIndex[x] = len(categories)
+			categories = append(categories, x)
+		}
+	}
+
+	type series struct {
+		name   string
+		values []any
+	}
+	var list []*series
+	if s.Series != "" {
+		byName := make(map[string]*series)
+		for _, r := range rows {
+			name := cellString(r[col["series"]])
+			sr, ok := byName[name]
+			if !ok {
+				sr = &series{name: name, values: make([]any, len(categories))}
+				byName[name] = sr
+				list = append(list, sr)
+			}
+			sr.values[catIndex[cellString(r[col["x"]])]] = cellValue(r[col["y[0]"]])
+		}
+	} else {
+		for i, y := range s.Y {
+			sr := &series{name: y, values: make([]any, len(categories))}
+			for _, r := range rows {
+				sr.values[catIndex[cellString(r[col["x"]])]] = cellValue(r[col[fmt.Sprintf("y[%d]", i)]])
+			}
+			list = append(list, sr)
+		}
+	}
+
+	global := []charts.GlobalOpts{
+		charts.WithTitleOpts(c.specTitle(s)),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "axis"}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(len(list) > 1)}),
+	}
+	xName, yName := c.specAxisName(s.XAxis, s.X), c.specAxisName(s.YAxis, strings.Join(s.Y, ", "))
+	if s.Type == SpecLine {
+		line := charts.NewLine()
+		line.SetGlobalOptions(append(global,
+			charts.WithXAxisOpts(opts.XAxis{Name: xName, Type: "category"}),
+			charts.WithYAxisOpts(opts.YAxis{Name: yName, Type: valueAxisType(s.YAxis), AxisLabel: c.axisLabel(s.YAxis.Format)}),
+		)...)
+		line.SetXAxis(categories)
+		for _, sr := range list {
+			data := make([]opts.LineData, len(sr.values))
+			for i, v := range sr.values {
+				data[i] = opts.LineData{Value: v}
+			}
+			line.AddSeries(c.printer.T(sr.name), data)
+		}
+		line.SetSeriesOptions(charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(true)}))
+		return line
+	}
+
+	bar := charts.NewBar()
+	if s.Type == SpecHBar {
+		global = append(global,
+			charts.WithYAxisOpts(opts.YAxis{Name: xName, Type: "category", Data: categories}),
+			charts.WithXAxisOpts(opts.XAxis{Name: yName, Type: valueAxisType(s.YAxis), AxisLabel: c.axisLabel(s.YAxis.Format)}),
+		)
+	} else {
+		global = append(global,
+			charts.WithXAxisOpts(opts.XAxis{Name: xName, Type: "category"}),
+			charts.WithYAxisOpts(opts.YAxis{Name: yName, Type: valueAxisType(s.YAxis), AxisLabel: c.axisLabel(s.YAxis.Format)}),
+		)
+		bar.SetXAxis(categories)
+	}
+	bar.SetGlobalOptions(global...)
+	for _, sr := range list {
+		data := make([]opts.BarData, len(sr.values))
+		for i, v := range sr.values {
+			data[i] = opts.BarData{Value: v}
+		}
+		bar.AddSeries(c.printer.T(sr.name), data)
+	}
+	return bar
+}
+
+func (c *Charts) specPie(s *Spec, rows [][]any, col map[string]int) ChartRenderer {
+	items := make([]opts.PieData, 0, len(rows))
+	for _, r := range rows {
+		items = append(items, opts.PieData{Name: c.printer.T(cellString(r[col["x"]])), Value: cellValue(r[col["y[0]"]])})
+	}
+	pie := charts.NewPie()
+	pie.SetGlobalOptions(
+		charts.WithTitleOpts(c.specTitle(s)),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+	)
+	pie.AddSeries(c.printer.T(s.Y[0]), items).SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(true), Formatter: "{b}: {c} ({d}%)"}),
+	)
+	return pie
+}
+
+func (c *Charts) specScatter(s *Spec, rows [][]any, col map[string]int) ChartRenderer {
+	points := make([]opts.ScatterData, 0, len(rows))
+	var colors []float64
+	for _, r := range rows {
+		x, okX := cellFloat(r[col["x"]])
+		y, okY := cellFloat(r[col["y[0]"]])
+		if !okX || !okY {
+			continue
+		}
+		value := []any{x, y}
+		if s.Color != "" {
+			v, _ := cellFloat(r[col["color"]])
+			value = append(value, v)
+			colors = append(colors, v)
+		}
+		p := opts.ScatterData{Value: value}
+		if s.Label != "" {
+			p.Name = cellString(r[col["label"]])
+		}
+		points = append(points, p)
+	}
+	scatter := charts.NewScatter()
+	scatter.SetGlobalOptions(
+		charts.WithTitleOpts(c.specTitle(s)),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.specAxisName(s.XAxis, s.X), Type: valueAxisType(s.XAxis), AxisLabel: c.axisLabel(s.XAxis.Format)}),
+		charts.WithYAxisOpts(opts.YAxis{Name: c.specAxisName(s.YAxis, s.Y[0]), Type: valueAxisType(s.YAxis), AxisLabel: c.axisLabel(s.YAxis.Format)}),
+	)
+	if len(colors) > 0 {
+		lo, hi := colors[0], colors[0]
+		for _, v := range colors {
+			lo, hi = math.Min(lo, v), math.Max(hi, v)
+		}
+		scatter.SetGlobalOptions(charts.WithVisualMapOpts(opts.VisualMap{
+			Type:       "continuous",
+			Calculable: opts.Bool(true),
+			Min:        float32(lo),
+			Max:        float32(hi),
+			Dimension:  "2",
+			Text:       []string{s.Color, ""},
+			Right:      "2%",
+			Top:        "middle",
+			InRange:    &opts.VisualMapInRange{Color: c.theme.Diverging},
+		}))
+	}
+	scatter.AddSeries(c.printer.T(s.Y[0]), points)
+	return scatter
+}
+
+// cellFloat converts a pgx-decoded value to float64; ok is false for NULL and
+// non-numeric values.
+func cellFloat(v any) (float64, bool) {
+	switch t := v.(type) {
+	case int:
+		return float64(t), true
+	case int16:
+		return float64(t), true
+	case int32:
+		return float64(t), true
+	case int64:
+		return float64(t), true
+	case float32:
+		return float64(t), true
+	case float64:
+		return t, true
+	case pgtype.Numeric:
+		f, err := t.Float64Value()
+		return f.Float64, err == nil && f.Valid
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// cellString formats a pgx-decoded value as a category label.
+func cellString(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case time.Time:
+		return t.Format(time.DateOnly)
+	case pgtype.Numeric:
+		if f, ok := cellFloat(t); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+		return ""
+	}
+	return fmt.Sprint(v)
+}
+
+// cellValue is the chart value of a cell: a number, or nil for a gap.
+func cellValue(v any) any {
+	if f, ok := cellFloat(v); ok {
+		return f
+	}
+	return nil
+}
+
+// compareCells orders numbers numerically and everything else as text, with
+// NULLs first.
+func compareCells(a, b any) int {
+	if a == nil || b == nil {
+		return cmp.Compare(boolInt(a != nil), boolInt(b != nil))
+	}
+	fa, okA := cellFloat(a)
+	fb, okB := cellFloat(b)
+	if okA && okB {
+		return cmp.Compare(fa, fb)
+	}
+	return strings.Compare(cellString(a), cellString(b))
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
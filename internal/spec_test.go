@@ -0,0 +1,177 @@
+package internal
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const validSpec = `name: budgets
+type: bar
+query: CountryProductionStats
+x: country_name
+y: [avg_budget, avg_revenue]
+`
+
+func TestParseSpec(t *testing.T) {
+	s, err := ParseSpec("specs/budgets.yaml", []byte(validSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Output != "budgets.html" || len(s.Y) != 2 {
+		t.Errorf("spec = %+v, want output budgets.html and two y columns", s)
+	}
+	// the name defaults to the file's, y takes a single string
+	s, err = ParseSpec("specs/runtime.json", []byte(`{"type": "pie", "query": "GenreAverageMetrics", "x": "genre_name", "y": "movies_count"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "runtime" || len(s.Y) != 1 {
+		t.Errorf("spec = %+v, want name runtime and one y column", s)
+	}
+}
+
+func TestSpecErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, spec string
+		want       []string // one SpecError each, in file order
+	}{
+		{"unknown chart type", "type: radar\nquery: DecadeTrends\nx: decade\ny: movies_count\n",
+			[]string{`s.yaml:1: type: unknown chart type "radar" (want bar, hbar, line, pie or scatter)`}},
+		{"missing type", "query: DecadeTrends\nx: decade\ny: movies_count\n",
+			[]string{`s.yaml: type: is required (bar, hbar, line, pie or scatter)`}},
+		{"missing query", "type: line\nx: decade\ny: movies_count\n",
+			[]string{`s.yaml: query: either query or sql is required`}},
+		{"unknown query", "type: line\nx: decade\n\nquery: Decades\ny: movies_count\n",
+			[]string{`s.yaml:4: query: unknown query "Decades" (want one of ActorRoleCounts,`}},
+		{"query and sql", "type: line\nquery: DecadeTrends\nsql: SELECT 1\nx: decade\ny: movies_count\n",
+			[]string{`s.yaml:3: sql: query and sql are mutually exclusive`}},
+		{"bad axis format", "type: bar\nquery: DecadeTrends\nx: decade\ny: movies_count\ny_axis:\n  name: Movies\n  format: euro\n",
+			[]string{`s.yaml:7: y_axis.format: unknown format "euro" (want compact, number, money or percent)`}},
+		{"empty y item", "type: line\nquery: DecadeTrends\nx: decade\ny:\n  - movies_count\n  - \"\"\n",
+			[]string{`s.yaml:6: y[1]: column name is empty`}},
+		{"missing field falls back to its parent", "type: bar\nquery: DecadeTrends\nx: decade\ny: movies_count\nsort: {order: up}\n",
+			[]string{`s.yaml:5: sort.field: is required`, `s.yaml:5: sort.order: "up" must be asc or desc`}},
+		{"every error in file order", "name: Bad-Name\ntype: pie\nquery: DecadeTrends\nx: decade\ny: [movies_count, avg_rating]\nlimit: -1\noutput: ../out.html\n",
+			[]string{
+				`s.yaml:1: name: "Bad-Name" must be lowercase letters, digits and underscores`,
+				`s.yaml:5: y[1]: pie charts take a single y column`,
+				`s.yaml:6: limit: must not be negative`,
+				`s.yaml:7: output: "../out.html" must be a plain .html file name`,
+			}},
+	} {
+		_, err := ParseSpec("s.yaml", []byte(tc.spec))
+		var got []string
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			for _, e := range joined.Unwrap() {
+				var se *SpecError
+				if !errors.As(e, &se) {
+					t.Errorf("%s: %T %v is not a SpecError", tc.name, e, e)
+				}
+				got = append(got, e.Error())
+			}
+		} else if err != nil {
+			got = []string{err.Error()}
+		}
+		if len(got) != len(tc.want) {
+			t.Errorf("%s: errors %q, want %q", tc.name, got, tc.want)
+			continue
+		}
+		for i := range got {
+			if !strings.HasPrefix(got[i], tc.want[i]) {
+				t.Errorf("%s: error %d = %q, want %q", tc.name, i, got[i], tc.want[i])
+			}
+		}
+	}
+}
+
+func TestSpecDecodeErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name, spec, want string
+	}{
+		{"bad field type", "type: bar\nquery: DecadeTrends\nx: decade\ny: movies_count\nlimit: ten\n", "line 5: cannot unmarshal !!str `ten` into int"},
+		{"list for a string", "type: bar\nquery: DecadeTrends\nx: [decade]\ny: movies_count\n", "line 3: cannot unmarshal !!seq into string"},
+		{"unknown field", "type: bar\nquery: DecadeTrends\nx: decade\ny: movies_count\ncolour: red\n", "line 5: field colour not found"},
+	} {
+		_, err := ParseSpec("s.yaml", []byte(tc.spec))
+		if err == nil || !strings.HasPrefix(err.Error(), "s.yaml: ") || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: ParseSpec = %v, want s.yaml: ... %s", tc.name, err, tc.want)
+		}
+	}
+}
+
+func TestLoadSpecs(t *testing.T) {
+	specs, err := LoadSpecs("../specs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) == 0 {
+		t.Fatal("no specs in ../specs")
+	}
+
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"a.yaml": validSpec,
+		"b.yml":  validSpec,          // same name as a.yaml
+		"c.json": `{"type": "area"}`, // reported along with the others
+		"d.txt":  "not a spec",       // skipped
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, err = LoadSpecs(dir)
+	for _, want := range []string{
+		filepath.Join(dir, "b.yml") + `:1: name: "budgets" is also defined in ` + filepath.Join(dir, "a.yaml"),
+		filepath.Join(dir, "c.json") + `:1: type: unknown chart type "area"`,
+	} {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadSpecs = %v, want it to report %q", err, want)
+		}
+	}
+}
+
+func TestRegisterSpecsOutputs(t *testing.T) {
+	parse := func(spec string) *Spec {
+		s, err := ParseSpec("specs/s.yaml", []byte(spec))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	for _, tc := range []struct {
+		name, output, want string
+	}{
+		{"built-in chart's file", "bar.html", `specs/s.yaml:6: output: "bar.html" is already written by chart "bar"`},
+		{"dashboard", "dashboard.html", `output: "dashboard.html" is already written by the dashboard`},
+		{"index", "index.html", `output: "index.html" is already written by the index`},
+		{"built-in chart's name", "", `specs/s.yaml:1: name: chart "bar" already registered`},
+	} {
+		spec := validSpec
+		if tc.output != "" {
+			spec += "output: " + tc.output + "\n"
+		} else {
+			spec = strings.Replace(spec, "name: budgets", "name: bar", 1)
+		}
+		if err := DefaultRegistry().Clone().RegisterSpecs(parse(spec)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: RegisterSpecs = %v, want %q", tc.name, err, tc.want)
+		}
+	}
+
+	// two specs sharing a file: the second is reported, the first registered
+	r := DefaultRegistry().Clone()
+	a := parse(strings.Replace(validSpec, "budgets", "a", 1) + "output: shared.html\n")
+	b := parse(strings.Replace(validSpec, "budgets", "b", 1) + "output: shared.html\n")
+	err := r.RegisterSpecs(a, b)
+	if err == nil || !strings.Contains(err.Error(), `"shared.html" is already written by chart "a"`) {
+		t.Errorf("RegisterSpecs = %v, want the shared output reported", err)
+	}
+	if _, ok := r.Get("a"); !ok {
+		t.Error("the first spec was not registered")
+	}
+	if err := DefaultRegistry().Clone().RegisterSpecs(parse(validSpec)); err != nil {
+		t.Errorf("RegisterSpecs of a spec with its own file = %v", err)
+	}
+}
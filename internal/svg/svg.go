@@ -0,0 +1,1138 @@
+// Package svg draws static SVG images from ECharts option maps, so the charts
+// built with go-echarts can be rendered without a browser. It covers the subset
+// of ECharts used by this project: bar (vertical, horizontal and value-axis
+// histograms), line, scatter with a continuous visualMap and coordinate mark
+// lines, pie, word cloud, treemap, and map series drawn on caller-supplied
+// shapes. Other series types are an error rather than a blank image.
+package svg
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var defaultPalette = []string{"#5470c6", "#91cc75", "#fac858", "#ee6666", "#73c0de", "#3ba272", "#fc8452", "#9a60b4", "#ea7ccc"}
+
+// Style carries the colors and font applied to every SVG.
+type Style struct {
+	Background string
+	Text       string
+	Subtle     string // axis lines, subtitles
+	Grid       string
+	Font       string
+	Number     func(float64) string // tick and tooltip labels; nil abbreviates as 12.5M
+}
+
+// Render writes option, as produced by a go-echarts chart's JSON() and
+// normalized through encoding/json, as a width×height SVG image.
+func Render(w io.Writer, option map[string]any, width, height int, style Style) error {
+	c := begin(option, width, height, style)
+	series := list(option["series"])
+	if len(series) == 0 {
+		return fmt.Errorf("svg: chart has no series")
+	}
+	var err error
+	switch str(obj(series[0])["type"]) {
+	case "pie":
+		err = c.pie(obj(series[0]))
+	case "wordCloud":
+		err = c.wordCloud(obj(series[0]))
+	case "treemap":
+		err = c.treemap(obj(series[0]))
+	default:
+		for _, raw := range series {
+			if t := str(obj(raw)["type"]); !cartesianTypes[t] {
+				return fmt.Errorf("svg: unsupported series type %q", t)
+			}
+		}
+		err = c.cartesian(series)
+	}
+	if err != nil {
+		return err
+	}
+	c.legend(series)
+	return c.end(w)
+}
+
+// cartesianTypes are the series types drawn on x/y axes.
+var cartesianTypes = map[string]bool{"bar": true, "line": true, "scatter": true}
+
+// begin opens the image and draws the background and title.
+func begin(option map[string]any, width, height int, style Style) *canvas {
+	c := &canvas{w: float64(width), h: float64(height), opt: option, style: style}
+	c.palette = defaultPalette
+	if colors := strings2(option["color"]); len(colors) > 0 {
+		c.palette = colors
+	}
+	if bg, ok := option["backgroundColor"].(string); ok && bg != "" {
+		c.style.Background = bg
+	}
+
+	fmt.Fprintf(&c.b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="%s">`+"\n", width, height, width, height, esc(c.style.Font))
+	fmt.Fprintf(&c.b, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", esc(c.style.Background))
+	c.title()
+	return c
+}
+
+func (c *canvas) end(w io.Writer) error {
+	c.b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, c.b.String())
+	return err
+}
+
+type canvas struct {
+	w, h    float64
+	opt     map[string]any
+	style   Style
+	palette []string
+	clips   int // clip paths written, for unique ids
+	b       strings.Builder
+}
+
+func (c *canvas) num(v float64) string {
+	if c.style.Number != nil {
+		return c.style.Number(v)
+	}
+	return formatNumber(v)
+}
+
+func (c *canvas) color(i int) string { return c.palette[i%len(c.palette)] }
+
+func (c *canvas) text(x, y float64, size int, fill, anchor, s string, extra string) {
+	fmt.Fprintf(&c.b, `<text x="%.1f" y="%.1f" font-size="%d" fill="%s" text-anchor="%s"%s>%s</text>`+"\n", x, y, size, esc(fill), anchor, extra, esc(s))
+}
+
+func (c *canvas) line(x1, y1, x2, y2 float64, stroke string, width float64, extra string) {
+	fmt.Fprintf(&c.b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="%s" stroke-width="%.1f"%s/>`+"\n", x1, y1, x2, y2, esc(stroke), width, extra)
+}
+
+func (c *canvas) title() {
+	t := obj(c.opt["title"])
+	if s := str(t["text"]); s != "" {
+		c.text(16, 28, 18, c.style.Text, "start", s, ` font-weight="bold"`)
+	}
+	if s := str(t["subtext"]); s != "" {
+		c.text(16, 48, 12, c.style.Subtle, "start", s, "")
+	}
+}
+
+func (c *canvas) legend(series []any) {
+	if l := obj(c.opt["legend"]); l["show"] == false {
+		return
+	}
+	// right-aligned rows in the right half, clear of the title
+	x, y := c.w-16, 18.0
+	for i := len(series) - 1; i >= 0; i-- {
+		s := obj(series[i])
+		name := str(s["name"])
+		if t := str(s["type"]); name == "" || t == "pie" || t == "wordCloud" || t == "treemap" {
+			continue
+		}
+		width := float64(len([]rune(name)))*6.5 + 22
+		if x-width < c.w/2 && x < c.w-16 {
+			x, y = c.w-16, y+16
+		}
+		x -= width
+		fmt.Fprintf(&c.b, `<rect x="%.1f" y="%.0f" width="14" height="10" rx="2" fill="%s"/>`+"\n", x, y, esc(c.color(i)))
+		c.text(x+18, y+9, 12, c.style.Text, "start", name, "")
+	}
+}
+
+// --- cartesian charts ---
+
+type point struct {
+	x, y   float64
+	x0, x1 float64 // explicit bar extents on a value x axis (histograms)
+	hasExt bool
+	name   string
+	color  string
+	size   float64 // scatter symbol diameter, 0 for the default
+	dims   []float64
+}
+
+type axis struct {
+	category bool
+	cats     []string
+	log      bool
+	min, max float64
+	name     string
+	used     bool
+}
+
+func (a *axis) extend(v float64) {
+	if a.log && v <= 0 {
+		return
+	}
+	if !a.used {
+		a.min, a.max, a.used = v, v, true
+		return
+	}
+	a.min = math.Min(a.min, v)
+	a.max = math.Max(a.max, v)
+}
+
+func (a *axis) t(v float64) float64 {
+	if a.log {
+		return math.Log10(v)
+	}
+	return v
+}
+
+// frac maps a value (or category index) to 0..1 along the axis.
+func (a *axis) frac(v float64) float64 {
+	if a.category {
+		n := math.Max(float64(len(a.cats)), 1)
+		return (v + 0.5) / n
+	}
+	lo, hi := a.t(a.min), a.t(a.max)
+	if hi == lo {
+		return 0.5
+	}
+	return (a.t(v) - lo) / (hi - lo)
+}
+
+func (a *axis) ticks() []float64 {
+	if a.category {
+		return nil
+	}
+	if a.log {
+		if !a.used {
+			// nothing positive to place
+			a.min, a.max = 1, 10
+		}
+		var out []float64
+		for e := math.Floor(math.Log10(a.min)); e <= math.Ceil(math.Log10(a.max)); e++ {
+			out = append(out, math.Pow(10, e))
+		}
+		a.min, a.max = out[0], out[len(out)-1]
+		return out
+	}
+	step := niceStep((a.max - a.min) / 5)
+	a.min = math.Floor(a.min/step) * step
+	a.max = math.Ceil(a.max/step) * step
+	if a.max == a.min {
+		a.max = a.min + step
+	}
+	var out []float64
+	for v := a.min; v <= a.max+step/2; v += step {
+		out = append(out, v)
+	}
+	return out
+}
+
+func newAxis(o map[string]any) *axis {
+	a := &axis{name: str(o["name"]), log: str(o["type"]) == "log"}
+	if cats := list(o["data"]); len(cats) > 0 || str(o["type"]) == "category" {
+		a.category = true
+		for _, v := range cats {
+			a.cats = append(a.cats, fmt.Sprint(v))
+		}
+	}
+	return a
+}
+
+// cartesian draws one panel per grid, stacked top to bottom as the option's
+// grid tops and heights place them; series and axes go to the grid of their
+// x axis.
+func (c *canvas) cartesian(series []any) error {
+	xa := list(c.opt["xAxis"])
+	ya := list(c.opt["yAxis"])
+	if len(xa) == 0 || len(ya) == 0 {
+		return fmt.Errorf("svg: cartesian chart without axes")
+	}
+	grids := list(c.opt["grid"])
+	top, bottom := 70.0, c.h-56
+	for g := range xa {
+		gridTop, gridBottom := top, bottom
+		if len(xa) > 1 {
+			gridTop, gridBottom = top+float64(g)*(bottom-top)/float64(len(xa)), top+float64(g+1)*(bottom-top)/float64(len(xa))-40
+			if g < len(grids) {
+				grid := obj(grids[g])
+				if t, ok := percent(grid["top"]); ok {
+					gridTop = c.h * t
+				}
+				if h, ok := percent(grid["height"]); ok {
+					gridBottom = gridTop + c.h*h
+				}
+			}
+		}
+		ys := make([]*axis, len(ya))
+		for i := range ya {
+			if int(num(obj(ya[i])["gridIndex"])) == g {
+				ys[i] = newAxis(obj(ya[i]))
+			}
+		}
+		var panel []int
+		for i, raw := range series {
+			if int(num(obj(raw)["xAxisIndex"])) == g && ys[yIndex(obj(raw), len(ys))] != nil {
+				panel = append(panel, i)
+			}
+		}
+		if err := c.grid(series, panel, newAxis(obj(xa[g])), ys, gridTop, gridBottom); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// percent reads a grid position like "55%" as a fraction of the image.
+func percent(v any) (float64, bool) {
+	s, ok := v.(string)
+	if !ok || !strings.HasSuffix(s, "%") {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	return f / 100, err == nil
+}
+
+// grid draws the series at the indexes in panel between top and bottom; ys
+// holds the grid's y axes at their option index and nil for other grids'.
+func (c *canvas) grid(series []any, panel []int, x *axis, ys []*axis, top, bottom float64) error {
+	var first *axis
+	yAxes := 0
+	for _, y := range ys {
+		if y != nil {
+			if first == nil {
+				first = y
+			}
+			yAxes++
+		}
+	}
+	if first == nil {
+		return fmt.Errorf("svg: grid without y axis")
+	}
+	horizontal := first.category && !x.category
+
+	points := make([][]point, len(series))
+	for _, i := range panel {
+		s := obj(series[i])
+		y := ys[yIndex(s, len(ys))]
+		pts := parsePoints(s, x.category, horizontal)
+		for _, p := range pts {
+			if horizontal {
+				x.extend(p.x)
+				continue
+			}
+			if !x.category {
+				x.extend(p.x)
+				if p.hasExt {
+					x.extend(p.x0)
+					x.extend(p.x1)
+				}
+			}
+			y.extend(p.y)
+		}
+		if str(s["type"]) == "bar" {
+			// bars grow from zero
+			if horizontal {
+				x.extend(0)
+			} else {
+				y.extend(0)
+			}
+		}
+		points[i] = pts
+		if x.category && len(x.cats) == 0 {
+			for j := range pts {
+				x.cats = append(x.cats, strconv.Itoa(j))
+			}
+		}
+	}
+	if !x.category && !x.used {
+		x.extend(0)
+	}
+	for _, y := range ys {
+		if y != nil && !y.category && !y.used {
+			y.extend(0)
+		}
+	}
+
+	left, right := 80.0, c.w-40
+	if horizontal {
+		left = 170
+	}
+	if yAxes > 1 {
+		right = c.w - 80
+	}
+	pw, ph := right-left, bottom-top
+	px := func(v float64) float64 { return left + x.frac(v)*pw }
+	py := func(y *axis, v float64) float64 { return bottom - y.frac(v)*ph }
+
+	// grid, ticks and labels
+	for _, t := range x.ticks() {
+		c.line(px(t), top, px(t), bottom, c.style.Grid, 1, "")
+		c.text(px(t), bottom+16, 11, c.style.Subtle, "middle", c.num(t), "")
+	}
+	if x.category {
+		step := int(math.Ceil(float64(len(x.cats)) / 20))
+		for i, cat := range x.cats {
+			if i%step == 0 {
+				c.text(px(float64(i)), bottom+16, 11, c.style.Subtle, "middle", truncate(cat, 14), "")
+			}
+		}
+	}
+	// the grid's first y axis is on the left, the others on the right
+	side := 0
+	for _, y := range ys {
+		if y == nil {
+			continue
+		}
+		i := side
+		side++
+		ticks := y.ticks()
+		lx, anchor := left-6, "end"
+		if i > 0 {
+			lx, anchor = right+6, "start"
+		}
+		for _, t := range ticks {
+			if i == 0 {
+				c.line(left, py(y, t), right, py(y, t), c.style.Grid, 1, "")
+			}
+			c.text(lx, py(y, t)+4, 11, c.style.Subtle, anchor, c.num(t), "")
+		}
+		if y.category {
+			for j, cat := range y.cats {
+				c.text(lx, py(y, float64(j))+4, 11, c.style.Subtle, anchor, truncate(cat, 26), "")
+			}
+		}
+	}
+	c.line(left, bottom, right, bottom, c.style.Subtle, 1, "")
+	c.line(left, top, left, bottom, c.style.Subtle, 1, "")
+	if x.name != "" {
+		c.text(left+pw/2, bottom+40, 12, c.style.Text, "middle", x.name, "")
+	}
+	side = 0
+	for _, y := range ys {
+		if y == nil {
+			continue
+		}
+		i := side
+		side++
+		if y.name == "" {
+			continue
+		}
+		ax := 18.0
+		if i > 0 {
+			ax = c.w - 14
+		}
+		if horizontal {
+			c.text(left-6, top-10, 12, c.style.Text, "end", y.name, "")
+			continue
+		}
+		c.text(ax, top+ph/2, 12, c.style.Text, "middle", y.name, fmt.Sprintf(` transform="rotate(-90 %.1f %.1f)"`, ax, top+ph/2))
+	}
+
+	c.clips++
+	fmt.Fprintf(&c.b, `<clipPath id="plot%d"><rect x="%.1f" y="%.1f" width="%.1f" height="%.1f"/></clipPath>`+"\n", c.clips, left, top, pw, ph)
+	fmt.Fprintf(&c.b, `<g clip-path="url(#plot%d)">`+"\n", c.clips)
+	vm := parseVisualMap(c.opt)
+	bars := 0
+	for _, i := range panel {
+		if str(obj(series[i])["type"]) == "bar" {
+			bars++
+		}
+	}
+	barSlot := 0
+	for _, i := range panel {
+		s := obj(series[i])
+		y := ys[yIndex(s, len(ys))]
+		color := c.color(i)
+		// a log axis has no place for zero or negative values
+		pts := slices.DeleteFunc(points[i], func(p point) bool {
+			return (x.log && (p.x <= 0 || p.hasExt && p.x0 <= 0)) || (y.log && p.y <= 0)
+		})
+		switch str(s["type"]) {
+		case "bar":
+			for _, p := range pts {
+				fill := p.color
+				if fill == "" {
+					fill = color
+				}
+				var rx, ry, rw, rh float64
+				switch {
+				case horizontal:
+					band := ph / math.Max(float64(len(y.cats)), 1) * 0.7 / float64(bars)
+					cy := py(y, p.y) - band*float64(bars)/2 + band*float64(barSlot)
+					x0, x1 := px(0), px(p.x)
+					rx, ry, rw, rh = math.Min(x0, x1), cy, math.Abs(x1-x0), band
+				case p.hasExt:
+					x0, x1 := px(p.x0), px(p.x1)
+					rx, rw = x0, math.Max(x1-x0-1, 1)
+					ry, rh = py(y, p.y), bottom-py(y, p.y)
+				default:
+					band := pw / math.Max(float64(len(x.cats)), 1) * 0.7 / float64(bars)
+					if !x.category {
+						band = 6
+					}
+					cx := px(p.x) - band*float64(bars)/2 + band*float64(barSlot)
+					y0, y1 := py(y, 0), py(y, p.y)
+					rx, ry, rw, rh = cx, math.Min(y0, y1), band, math.Abs(y1-y0)
+				}
+				fmt.Fprintf(&c.b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"><title>%s</title></rect>`+"\n", rx, ry, rw, rh, esc(fill), esc(c.pointTitle(p, horizontal)))
+			}
+			barSlot++
+		case "line":
+			if len(pts) == 0 {
+				break
+			}
+			var path strings.Builder
+			for j, p := range pts {
+				cmd := "L"
+				if j == 0 {
+					cmd = "M"
+				}
+				fmt.Fprintf(&path, "%s%.1f %.1f ", cmd, px(p.x), py(y, p.y))
+			}
+			if s["areaStyle"] != nil {
+				fmt.Fprintf(&c.b, `<path d="%sL%.1f %.1f L%.1f %.1f Z" fill="%s" fill-opacity="0.18" stroke="none"/>`+"\n", path.String(), px(pts[len(pts)-1].x), bottom, px(pts[0].x), bottom, esc(color))
+			}
+			fmt.Fprintf(&c.b, `<path d="%s" fill="none" stroke="%s" stroke-width="2"/>`+"\n", path.String(), esc(color))
+		case "scatter":
+			showLabel := obj(s["label"])["show"] == true
+			for _, p := range pts {
+				fill := color
+				if vm != nil {
+					fill = vm.color(p.dims)
+				}
+				r := 4.0
+				if p.size > 0 {
+					r = p.size / 2
+				}
+				fmt.Fprintf(&c.b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s" fill-opacity="0.8"><title>%s</title></circle>`+"\n", px(p.x), py(y, p.y), r, esc(fill), esc(c.pointTitle(p, false)))
+				if showLabel && p.name != "" {
+					c.text(px(p.x)+r+2, py(y, p.y)+4, 10, c.style.Text, "start", p.name, "")
+				}
+			}
+		}
+		c.markLines(s, pts, x, y, px, py, color)
+	}
+	c.b.WriteString("</g>\n")
+	if vm != nil {
+		c.visualMapBar(vm)
+	}
+	return nil
+}
+
+func (c *canvas) markLines(s map[string]any, pts []point, x, y *axis, px func(float64) float64, py func(*axis, float64) float64, color string) {
+	ml := obj(s["markLine"])
+	dash := ` stroke-dasharray="6 4"`
+	for _, d := range list(ml["data"]) {
+		switch item := d.(type) {
+		case []any: // coordinate pair
+			if len(item) != 2 {
+				continue
+			}
+			a, b := floats(obj(item[0])["coord"]), floats(obj(item[1])["coord"])
+			if len(a) < 2 || len(b) < 2 {
+				continue
+			}
+			c.line(px(a[0]), py(y, a[1]), px(b[0]), py(y, b[1]), c.style.Subtle, 1.5, dash)
+			c.text(px(b[0])-4, py(y, b[1])-6, 11, c.style.Subtle, "end", str(obj(item[0])["name"]), "")
+		case map[string]any:
+			name := str(item["name"])
+			switch {
+			case item["xAxis"] != nil:
+				v := num(item["xAxis"])
+				c.line(px(v), py(y, y.min), px(v), py(y, y.max), color, 1.5, dash)
+				c.text(px(v)+4, py(y, y.max)+14, 11, color, "start", name, "")
+			case item["yAxis"] != nil:
+				v := num(item["yAxis"])
+				c.line(px(x.min), py(y, v), px(x.max), py(y, v), color, 1.5, dash)
+				c.text(px(x.max)-4, py(y, v)-6, 11, color, "end", name, "")
+			case str(item["type"]) == "average" && len(pts) > 0:
+				sum := 0.0
+				for _, p := range pts {
+					sum += p.y
+				}
+				v := sum / float64(len(pts))
+				c.line(px(x.min), py(y, v), px(x.max), py(y, v), color, 1.5, dash)
+				c.text(px(x.max)-4, py(y, v)-6, 11, color, "end", fmt.Sprintf("%s %s", name, c.num(v)), "")
+			}
+		}
+	}
+}
+
+func parsePoints(s map[string]any, categoryX, horizontal bool) []point {
+	data := list(s["data"])
+	out := make([]point, 0, len(data))
+	for i, d := range data {
+		p := point{}
+		var v any = d
+		if m, ok := d.(map[string]any); ok {
+			v = m["value"]
+			p.name = str(m["name"])
+			p.color = str(obj(m["itemStyle"])["color"])
+			p.size = num(m["symbolSize"])
+		}
+		vals := floats(v)
+		if len(vals) == 0 {
+			continue
+		}
+		switch {
+		case len(vals) >= 2 && !categoryX && !horizontal:
+			p.x, p.y = vals[0], vals[1]
+			if len(vals) >= 4 && str(s["type"]) == "bar" {
+				p.x0, p.x1, p.hasExt = vals[2], vals[3], true
+			}
+		case horizontal:
+			p.x, p.y = vals[0], float64(i)
+		default:
+			p.x, p.y = float64(i), vals[0]
+		}
+		p.dims = vals
+		out = append(out, p)
+	}
+	return out
+}
+
+func (c *canvas) pointTitle(p point, horizontal bool) string {
+	v := p.y
+	if horizontal {
+		v = p.x
+	}
+	if p.hasExt {
+		return fmt.Sprintf("%s – %s: %s", c.num(p.x0), c.num(p.x1), c.num(v))
+	}
+	if p.name != "" {
+		parts := make([]string, len(p.dims))
+		for i, d := range p.dims {
+			parts[i] = c.num(d)
+		}
+		return p.name + ": " + strings.Join(parts, ", ")
+	}
+	return c.num(v)
+}
+
+func yIndex(s map[string]any, n int) int {
+	i := int(num(s["yAxisIndex"]))
+	if i < 0 || i >= n {
+		return 0
+	}
+	return i
+}
+
+// --- visualMap ---
+
+type visualMap struct {
+	min, max float64
+	dim      int
+	stops    [][3]float64
+	colors   []string
+	text     []string
+}
+
+func parseVisualMap(opt map[string]any) *visualMap {
+	maps := list(opt["visualMap"])
+	if len(maps) == 0 {
+		return nil
+	}
+	m := obj(maps[0])
+	colors := strings2(obj(m["inRange"])["color"])
+	if len(colors) < 2 {
+		return nil
+	}
+	vm := &visualMap{min: num(m["min"]), max: num(m["max"]), colors: colors, text: strings2(m["text"])}
+	// go-echarts writes the dimension as a string, ECharts also takes a number
+	vm.dim = int(num(m["dimension"]))
+	for _, col := range colors {
+		vm.stops = append(vm.stops, parseHex(col))
+	}
+	return vm
+}
+
+func (vm *visualMap) color(dims []float64) string {
+	if vm.dim >= len(dims) {
+		return vm.colors[0]
+	}
+	t := 0.0
+	if vm.max > vm.min {
+		t = (dims[vm.dim] - vm.min) / (vm.max - vm.min)
+	}
+	return blend(vm.stops, t)
+}
+
+// Gradient returns the color at t, from 0 to 1, along colors, the way a
+// continuous visualMap shades values.
+func Gradient(colors []string, t float64) string {
+	stops := make([][3]float64, len(colors))
+	for i, col := range colors {
+		stops[i] = parseHex(col)
+	}
+	return blend(stops, t)
+}
+
+func blend(stops [][3]float64, t float64) string {
+	if len(stops) == 1 {
+		stops = append(stops, stops[0])
+	}
+	t = math.Max(0, math.Min(1, t))
+	seg := t * float64(len(stops)-1)
+	i := int(seg)
+	if i >= len(stops)-1 {
+		i = len(stops) - 2
+	}
+	f := seg - float64(i)
+	a, b := stops[i], stops[i+1]
+	return fmt.Sprintf("#%02x%02x%02x", int(a[0]+(b[0]-a[0])*f), int(a[1]+(b[1]-a[1])*f), int(a[2]+(b[2]-a[2])*f))
+}
+
+func (c *canvas) visualMapBar(vm *visualMap) {
+	x, top, h := c.w-26, c.h/2-60, 120.0
+	c.b.WriteString(`<defs><linearGradient id="vm" x1="0" y1="1" x2="0" y2="0">`)
+	for i, col := range vm.colors {
+		fmt.Fprintf(&c.b, `<stop offset="%.2f" stop-color="%s"/>`, float64(i)/float64(len(vm.colors)-1), esc(col))
+	}
+	c.b.WriteString("</linearGradient></defs>\n")
+	fmt.Fprintf(&c.b, `<rect x="%.1f" y="%.1f" width="12" height="%.1f" fill="url(#vm)"/>`+"\n", x, top, h)
+	c.text(x+6, top-6, 10, c.style.Subtle, "middle", c.num(vm.max), "")
+	c.text(x+6, top+h+14, 10, c.style.Subtle, "middle", c.num(vm.min), "")
+	if len(vm.text) > 0 && vm.text[0] != "" {
+		c.text(x+6, top-20, 10, c.style.Text, "middle", vm.text[0], "")
+	}
+}
+
+// --- pie ---
+
+func (c *canvas) pie(s map[string]any) error {
+	type slice struct {
+		name  string
+		value float64
+	}
+	var slices []slice
+	total := 0.0
+	for _, d := range list(s["data"]) {
+		m := obj(d)
+		v := num(m["value"])
+		if v <= 0 {
+			continue
+		}
+		slices = append(slices, slice{str(m["name"]), v})
+		total += v
+	}
+	if total == 0 {
+		return fmt.Errorf("svg: pie has no positive values")
+	}
+	cx, cy := c.w/2, c.h/2+20
+	r := math.Min(c.w, c.h) * 0.3
+	angle := -math.Pi / 2
+	for i, sl := range slices {
+		sweep := sl.value / total * 2 * math.Pi
+		end := angle + sweep
+		large := 0
+		if sweep > math.Pi {
+			large = 1
+		}
+		color := c.color(i)
+		if len(slices) == 1 {
+			fmt.Fprintf(&c.b, `<circle cx="%.1f" cy="%.1f" r="%.1f" fill="%s"/>`+"\n", cx, cy, r, esc(color))
+		} else {
+			fmt.Fprintf(&c.b, `<path d="M%.1f %.1f L%.1f %.1f A%.1f %.1f 0 %d 1 %.1f %.1f Z" fill="%s" stroke="%s" stroke-width="1"><title>%s</title></path>`+"\n",
+				cx, cy, cx+r*math.Cos(angle), cy+r*math.Sin(angle), r, r, large, cx+r*math.Cos(end), cy+r*math.Sin(end), esc(color), esc(c.style.Background), esc(sl.name))
+		}
+		mid := angle + sweep/2
+		lx, ly := cx+(r+18)*math.Cos(mid), cy+(r+18)*math.Sin(mid)
+		anchor := "start"
+		if math.Cos(mid) < 0 {
+			anchor = "end"
+		}
+		c.line(cx+r*math.Cos(mid), cy+r*math.Sin(mid), lx, ly, color, 1, "")
+		c.text(lx, ly+4, 12, c.style.Text, anchor, fmt.Sprintf("%s: %s (%.1f%%)", sl.name, c.num(sl.value), sl.value/total*100), "")
+		angle = end
+	}
+	return nil
+}
+
+// --- word cloud ---
+
+// wordCloud places the words largest first along a spiral from the center,
+// skipping those that no longer fit. Text widths are estimated from the font
+// size, so the layout is looser than the browser's.
+func (c *canvas) wordCloud(s map[string]any) error {
+	type word struct {
+		name  string
+		value float64
+		color string
+	}
+	var words []word
+	for _, d := range list(s["data"]) {
+		m := obj(d)
+		words = append(words, word{str(m["name"]), num(m["value"]), str(obj(m["textStyle"])["color"])})
+	}
+	if len(words) == 0 {
+		return fmt.Errorf("svg: word cloud has no words")
+	}
+	sort.SliceStable(words, func(i, j int) bool { return words[i].value > words[j].value })
+	lo, hi := 12.0, 60.0
+	if r := floats(s["sizeRange"]); len(r) == 2 {
+		lo, hi = r[0], r[1]
+	}
+	vmin, vmax := words[len(words)-1].value, words[0].value
+
+	type box struct{ x0, y0, x1, y1 float64 }
+	left, top, right, bottom := 16.0, 64.0, c.w-16, c.h-16
+	cx, cy := (left+right)/2, (top+bottom)/2
+	var placed []box
+	for i, w := range words {
+		size := lo
+		if vmax > vmin {
+			size += (w.value - vmin) / (vmax - vmin) * (hi - lo)
+		}
+		bw, bh := float64(len([]rune(w.name)))*size*0.58, size
+		for t := 0.0; t < 200; t += 0.1 {
+			// a wide spiral, to match the image's aspect ratio
+			x, y := cx+4*t*math.Cos(t)*(right-left)/(bottom-top), cy+4*t*math.Sin(t)
+			b := box{x - bw/2, y - bh/2, x + bw/2, y + bh/2}
+			if b.x0 < left || b.x1 > right || b.y0 < top || b.y1 > bottom {
+				continue
+			}
+			free := true
+			for _, p := range placed {
+				if b.x0 < p.x1 && p.x0 < b.x1 && b.y0 < p.y1 && p.y0 < b.y1 {
+					free = false
+					break
+				}
+			}
+			if !free {
+				continue
+			}
+			placed = append(placed, b)
+			color := w.color
+			if color == "" {
+				color = c.color(i)
+			}
+			fmt.Fprintf(&c.b, `<text x="%.1f" y="%.1f" font-size="%.0f" fill="%s" text-anchor="middle" dominant-baseline="central">%s<title>%s: %s</title></text>`+"\n",
+				x, y, size, esc(color), esc(w.name), esc(w.name), esc(c.num(w.value)))
+			break
+		}
+	}
+	return nil
+}
+
+// --- treemap ---
+
+type rect struct{ x, y, w, h float64 }
+
+// treemap draws the top-level nodes as labelled groups of their children,
+// each area proportional to the node's (first) value.
+func (c *canvas) treemap(s map[string]any) error {
+	nodes := list(s["data"])
+	if len(nodes) == 0 {
+		return fmt.Errorf("svg: treemap has no data")
+	}
+	values := make([]float64, len(nodes))
+	for i, n := range nodes {
+		values[i] = num(obj(n)["value"])
+	}
+	for i, r := range squarify(values, rect{16, 64, c.w - 32, c.h - 80}) {
+		n := obj(nodes[i])
+		children := list(n["children"])
+		if len(children) == 0 {
+			c.treemapLeaf(n, r, c.color(i))
+			continue
+		}
+		fmt.Fprintf(&c.b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s"/>`+"\n", r.x, r.y, r.w, r.h, esc(c.style.Grid))
+		if r.h > 18 && r.w > 30 {
+			c.text(r.x+4, r.y+13, 12, c.style.Text, "start", str(n["name"]), ` font-weight="bold"`)
+			r = rect{r.x + 2, r.y + 18, r.w - 4, r.h - 20}
+		}
+		cv := make([]float64, len(children))
+		for j, ch := range children {
+			cv[j] = num(obj(ch)["value"])
+		}
+		for j, cr := range squarify(cv, r) {
+			c.treemapLeaf(obj(children[j]), cr, c.color(i))
+		}
+	}
+	return nil
+}
+
+func (c *canvas) treemapLeaf(n map[string]any, r rect, fallback string) {
+	if r.w <= 0 || r.h <= 0 {
+		return
+	}
+	fill := str(obj(n["itemStyle"])["color"])
+	if fill == "" {
+		fill = fallback
+	}
+	name := str(n["name"])
+	fmt.Fprintf(&c.b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="%s" stroke="%s" stroke-width="1"><title>%s: %s</title></rect>`+"\n",
+		r.x, r.y, r.w, r.h, esc(fill), esc(c.style.Background), esc(name), esc(c.num(num(n["value"]))))
+	if float64(len([]rune(name)))*6.5+8 < r.w && r.h > 18 {
+		c.text(r.x+4, r.y+14, 11, c.style.Text, "start", name, "")
+	}
+}
+
+// squarify splits r into one rectangle per value, in the order given, with
+// areas proportional to the values. Rows are filled along the shorter side
+// while that keeps the rectangles closer to squares (Bruls et al.).
+func squarify(values []float64, r rect) []rect {
+	out := make([]rect, len(values))
+	order := make([]int, 0, len(values))
+	total := 0.0
+	for i, v := range values {
+		if v > 0 {
+			order = append(order, i)
+			total += v
+		}
+	}
+	if total == 0 {
+		return out
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] > values[order[b]] })
+	scale := r.w * r.h / total
+	worst := func(row []int, side float64) float64 {
+		sum, hi, lo := 0.0, 0.0, math.Inf(1)
+		for _, i := range row {
+			a := values[i] * scale
+			sum, hi, lo = sum+a, math.Max(hi, a), math.Min(lo, a)
+		}
+		return math.Max(side*side*hi/(sum*sum), sum*sum/(side*side*lo))
+	}
+	for len(order) > 0 {
+		side := math.Min(r.w, r.h)
+		n := 1
+		for n < len(order) && worst(order[:n+1], side) <= worst(order[:n], side) {
+			n++
+		}
+		sum := 0.0
+		for _, i := range order[:n] {
+			sum += values[i] * scale
+		}
+		thick, pos := sum/side, 0.0
+		for _, i := range order[:n] {
+			l := values[i] * scale / thick
+			if r.w >= r.h {
+				out[i] = rect{r.x, r.y + pos, thick, l}
+			} else {
+				out[i] = rect{r.x + pos, r.y, l, thick}
+			}
+			pos += l
+		}
+		if r.w >= r.h {
+			r.x, r.w = r.x+thick, r.w-thick
+		} else {
+			r.y, r.h = r.y+thick, r.h-thick
+		}
+		order = order[n:]
+	}
+	return out
+}
+
+// --- map ---
+
+// Shapes are the outlines of map regions by name: rings of [longitude,
+// latitude] points. Holes are drawn with the even-odd rule.
+type Shapes map[string][][][2]float64
+
+// RenderMap writes a choropleth of option's first map series: each region in
+// shapes is filled by its value through the option's visualMap, or with the
+// grid color when it has no data.
+func RenderMap(w io.Writer, option map[string]any, shapes Shapes, width, height int, style Style) error {
+	c := begin(option, width, height, style)
+	series := list(option["series"])
+	if len(series) == 0 || str(obj(series[0])["type"]) != "map" {
+		return fmt.Errorf("svg: chart has no map series")
+	}
+	values := make(map[string]float64)
+	for _, d := range list(obj(series[0])["data"]) {
+		m := obj(d)
+		values[str(m["name"])] = num(m["value"])
+	}
+	vm := parseVisualMap(c.opt)
+
+	// equirectangular projection of 180°W–180°E and 58°S–84°N, which leaves
+	// out Antarctica, fitted into the area below the title
+	const west, east, south, north = -180.0, 180.0, -58.0, 84.0
+	left, top, right, bottom := 16.0, 64.0, c.w-56, c.h-16
+	scale := math.Min((right-left)/(east-west), (bottom-top)/(north-south))
+	x0 := left + ((right-left)-scale*(east-west))/2
+	px := func(lon float64) float64 { return x0 + (lon-west)*scale }
+	py := func(lat float64) float64 { return top + (north-lat)*scale }
+
+	names := make([]string, 0, len(shapes))
+	for name := range shapes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		var d strings.Builder
+		for _, ring := range shapes[name] {
+			if !slices.ContainsFunc(ring, func(pt [2]float64) bool { return pt[1] > south }) {
+				continue
+			}
+			for i, pt := range ring {
+				cmd := "L"
+				if i == 0 {
+					cmd = "M"
+				}
+				fmt.Fprintf(&d, "%s%.1f %.1f", cmd, px(pt[0]), py(math.Max(south, pt[1])))
+			}
+			d.WriteString("Z")
+		}
+		if d.Len() == 0 {
+			continue
+		}
+		fill, label := c.style.Grid, name
+		if v, ok := values[name]; ok {
+			label += ": " + c.num(v)
+			if vm != nil {
+				fill = vm.color([]float64{v})
+			}
+		}
+		fmt.Fprintf(&c.b, `<path d="%s" fill="%s" fill-rule="evenodd" stroke="%s" stroke-width="0.5"><title>%s</title></path>`+"\n", d.String(), esc(fill), esc(c.style.Background), esc(label))
+	}
+	if vm != nil {
+		c.visualMapBar(vm)
+	}
+	return c.end(w)
+}
+
+// --- helpers ---
+
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	mag := math.Pow(10, math.Floor(math.Log10(raw)))
+	switch f := raw / mag; {
+	case f <= 1:
+		return mag
+	case f <= 2:
+		return 2 * mag
+	case f <= 5:
+		return 5 * mag
+	default:
+		return 10 * mag
+	}
+}
+
+// formatNumber abbreviates large magnitudes (12.5M) and trims small ones.
+func formatNumber(v float64) string {
+	a := math.Abs(v)
+	switch {
+	case a >= 1e9:
+		return trimFloat(v/1e9, 2) + "B"
+	case a >= 1e6:
+		return trimFloat(v/1e6, 1) + "M"
+	case a >= 1e4:
+		return trimFloat(v/1e3, 1) + "k"
+	case a >= 100 || a == math.Trunc(a):
+		return strconv.FormatFloat(v, 'f', 0, 64)
+	default:
+		return trimFloat(v, 2)
+	}
+}
+
+func trimFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+func parseHex(s string) [3]float64 {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 3 {
+		s = string([]byte{s[0], s[0], s[1], s[1], s[2], s[2]})
+	}
+	var out [3]float64
+	for i := 0; i < 3 && len(s) >= 2*i+2; i++ {
+		v, _ := strconv.ParseUint(s[2*i:2*i+2], 16, 8)
+		out[i] = float64(v)
+	}
+	return out
+}
+
+var escaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&#39;")
+
+func esc(s string) string { return escaper.Replace(s) }
+
+func obj(v any) map[string]any {
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+	if l, ok := v.([]any); ok && len(l) > 0 {
+		return obj(l[0])
+	}
+	return map[string]any{}
+}
+
+func list(v any) []any {
+	switch t := v.(type) {
+	case []any:
+		return t
+	case nil:
+		return nil
+	default:
+		return []any{t}
+	}
+}
+
+func str(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func num(v any) float64 {
+	switch t := v.(type) {
+	case float64:
+		return t
+	case string:
+		f, _ := strconv.ParseFloat(t, 64)
+		return f
+	case []any:
+		if len(t) > 0 {
+			return num(t[0])
+		}
+	}
+	return 0
+}
+
+func floats(v any) []float64 {
+	switch t := v.(type) {
+	case float64:
+		return []float64{t}
+	case string:
+		f, err := strconv.ParseFloat(t, 64)
+		if err != nil {
+			return nil
+		}
+		return []float64{f}
+	case []any:
+		out := make([]float64, 0, len(t))
+		for _, e := range t {
+			if e == nil {
+				out = append(out, math.NaN())
+				continue
+			}
+			out = append(out, num(e))
+		}
+		return out
+	}
+	return nil
+}
+
+func strings2(v any) []string {
+	var out []string
+	for _, e := range list(v) {
+		if s, ok := e.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
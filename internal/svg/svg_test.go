@@ -0,0 +1,421 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var testStyle = Style{Background: "#ffffff", Text: "#333333", Subtle: "#6e7079", Grid: "#e0e6f1", Font: "sans-serif"}
+
+// option decodes an ECharts option the way charts hand it to Render.
+func option(t *testing.T, js string) map[string]any {
+	t.Helper()
+	var opt map[string]any
+	if err := json.Unmarshal([]byte(js), &opt); err != nil {
+		t.Fatal(err)
+	}
+	return opt
+}
+
+func render(t *testing.T, js string) string {
+	t.Helper()
+	var b bytes.Buffer
+	if err := Render(&b, option(t, js), 900, 500, testStyle); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if !strings.HasPrefix(out, "<svg ") || !strings.HasSuffix(out, "</svg>\n") {
+		t.Fatalf("not an SVG document:\n%s", out)
+	}
+	if strings.Contains(out, "NaN") || strings.Contains(out, "Inf") {
+		t.Fatalf("SVG has non-finite coordinates:\n%s", out)
+	}
+	return out
+}
+
+// attrs returns the float attributes of every element matching re, whose
+// submatches are the attribute values.
+func attrs(t *testing.T, svg, re string) [][]float64 {
+	t.Helper()
+	var out [][]float64
+	for _, m := range regexp.MustCompile(re).FindAllStringSubmatch(svg, -1) {
+		vals := make([]float64, len(m)-1)
+		for i, s := range m[1:] {
+			v, err := strconv.ParseFloat(s, 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+			vals[i] = v
+		}
+		out = append(out, vals)
+	}
+	return out
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 0.6 }
+
+const barRect = `<rect x="([-\d.]+)" y="([-\d.]+)" width="([-\d.]+)" height="([-\d.]+)" fill="[^"]+"><title>`
+
+func TestBar(t *testing.T) {
+	out := render(t, `{
+		"title": {"text": "Genres"},
+		"xAxis": [{"type": "category", "data": ["Drama", "Comedy", "Action"]}],
+		"yAxis": [{"name": "Movies"}],
+		"series": [{"type": "bar", "name": "Movies", "data": [{"value": 10}, {"value": 20}, {"value": 30}]}]
+	}`)
+	bars := attrs(t, out, barRect)
+	if len(bars) != 3 {
+		t.Fatalf("got %d bars, want 3", len(bars))
+	}
+	// bars grow from the same baseline in proportion to their value
+	for i, b := range bars {
+		if !near(b[1]+b[3], bars[0][1]+bars[0][3]) {
+			t.Errorf("bar %d ends at %.1f, want the baseline %.1f", i, b[1]+b[3], bars[0][1]+bars[0][3])
+		}
+		if !near(b[3], bars[0][3]*float64(i+1)) {
+			t.Errorf("bar %d is %.1f high, want %.1f", i, b[3], bars[0][3]*float64(i+1))
+		}
+	}
+	for _, s := range []string{">Genres<", ">Drama<", ">Movies<", "<title>30</title>"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("SVG lacks %s", s)
+		}
+	}
+}
+
+func TestHorizontalBar(t *testing.T) {
+	out := render(t, `{
+		"xAxis": [{"type": "value"}],
+		"yAxis": [{"type": "category", "data": ["A24", "Pixar"]}],
+		"series": [{"type": "bar", "data": [{"value": 5}, {"value": 15}]}]
+	}`)
+	bars := attrs(t, out, barRect)
+	if len(bars) != 2 {
+		t.Fatalf("got %d bars, want 2", len(bars))
+	}
+	if !near(bars[0][0], bars[1][0]) || !near(bars[1][2], 3*bars[0][2]) {
+		t.Errorf("bars %v do not grow from one edge in proportion", bars)
+	}
+	if bars[0][1] <= bars[1][1] {
+		t.Error("the first category should be drawn at the bottom")
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	// value x axis with [center, count, from, to] items
+	out := render(t, `{
+		"xAxis": [{"type": "value"}],
+		"yAxis": [{"type": "value"}],
+		"series": [{"type": "bar", "data": [{"value": [5, 4, 0, 10]}, {"value": [15, 8, 10, 20]}]}]
+	}`)
+	bars := attrs(t, out, barRect)
+	if len(bars) != 2 {
+		t.Fatalf("got %d bins, want 2", len(bars))
+	}
+	// bins span their extents, with a 1px gap in between
+	if !near(bars[0][0]+bars[0][2]+1, bars[1][0]) || !near(bars[0][2], bars[1][2]) {
+		t.Errorf("bins %v are not adjacent and equally wide", bars)
+	}
+	if !near(bars[1][3], 2*bars[0][3]) {
+		t.Errorf("bin heights %.1f and %.1f are not in proportion", bars[0][3], bars[1][3])
+	}
+	if !strings.Contains(out, "<title>10 – 20: 8</title>") {
+		t.Error("bin title lacks its range and count")
+	}
+}
+
+func TestLine(t *testing.T) {
+	out := render(t, `{
+		"legend": {"show": true},
+		"xAxis": [{"type": "category", "data": ["2000", "2001", "2002"]}],
+		"yAxis": [{"type": "value"}, {"type": "value", "position": "right", "name": "Rating"}],
+		"series": [
+			{"type": "line", "name": "Revenue", "data": [{"value": 1}, {"value": 3}, {"value": 2}], "areaStyle": {},
+			 "markLine": {"data": [{"type": "average", "name": "avg"}]}},
+			{"type": "line", "name": "Rating", "yAxisIndex": 1, "data": [{"value": 6.5}, {"value": 7}, {"value": 6}]}
+		]
+	}`)
+	paths := regexp.MustCompile(`<path d="(M[^"]+)" fill="none"`).FindAllStringSubmatch(out, -1)
+	if len(paths) != 2 {
+		t.Fatalf("got %d lines, want 2", len(paths))
+	}
+	if n := strings.Count(paths[0][1], "L"); n != 2 {
+		t.Errorf("line has %d segments, want 2", n)
+	}
+	if !strings.Contains(out, `fill-opacity="0.18"`) {
+		t.Error("area under the first line is missing")
+	}
+	if !strings.Contains(out, ">avg 2<") {
+		t.Error("average mark line is missing")
+	}
+	for _, s := range []string{">Revenue<", ">Rating<", `text-anchor="start">7`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("SVG lacks %s (legend or right axis)", s)
+		}
+	}
+}
+
+const circle = `<circle cx="([-\d.]+)" cy="([-\d.]+)" r="([-\d.]+)"`
+
+func TestScatter(t *testing.T) {
+	out := render(t, `{
+		"xAxis": [{"type": "value"}],
+		"yAxis": [{"type": "value"}],
+		"visualMap": [{"min": 0, "max": 2, "dimension": 2, "inRange": {"color": ["#000000", "#ffffff"]}}],
+		"series": [{"type": "scatter", "label": {"show": true},
+			"data": [{"name": "Low", "value": [1, 1, 0]}, {"name": "High", "value": [2, 2, 2], "symbolSize": 20}],
+			"markLine": {"data": [[{"name": "break-even", "coord": [0, 0]}, {"coord": [2, 2]}]]}}]
+	}`)
+	pts := attrs(t, out, circle)
+	if len(pts) != 2 {
+		t.Fatalf("got %d points, want 2", len(pts))
+	}
+	if pts[0][2] != 4 || pts[1][2] != 10 {
+		t.Errorf("radii %.0f and %.0f, want the default 4 and half of symbolSize", pts[0][2], pts[1][2])
+	}
+	for _, s := range []string{`fill="#000000" fill-opacity`, `fill="#ffffff" fill-opacity`, ">High<", ">break-even<", `stroke-dasharray`, `url(#vm)`} {
+		if !strings.Contains(out, s) {
+			t.Errorf("SVG lacks %s", s)
+		}
+	}
+}
+
+func TestScatterLogAxes(t *testing.T) {
+	out := render(t, `{
+		"xAxis": [{"type": "log"}],
+		"yAxis": [{"type": "log"}],
+		"series": [{"type": "scatter", "data": [{"value": [10, 100]}, {"value": [1000, 100000]}, {"value": [0, 50]}, {"value": [100, -1]}]}]
+	}`)
+	pts := attrs(t, out, circle)
+	if len(pts) != 2 {
+		t.Fatalf("got %d points, want 2: values <= 0 have no place on a log axis", len(pts))
+	}
+	// the axes snap to whole decades, so 10 and 1000 sit at the ends
+	if !near(pts[0][0], 80) || !near(pts[1][0], 900-40) {
+		t.Errorf("x positions %.1f and %.1f, want the axis ends", pts[0][0], pts[1][0])
+	}
+	for _, tick := range []string{">10<", ">100<", ">1000<", ">10k<", ">100k<"} {
+		if !strings.Contains(out, tick) {
+			t.Errorf("log axis lacks the %s tick", tick)
+		}
+	}
+
+	// nothing positive at all still gives a finite axis
+	out = render(t, `{"xAxis": [{"type": "log"}], "yAxis": [{"type": "log"}], "series": [{"type": "scatter", "data": [{"value": [0, 0]}]}]}`)
+	if n := len(attrs(t, out, circle)); n != 0 {
+		t.Errorf("got %d points, want none", n)
+	}
+}
+
+func TestGrids(t *testing.T) {
+	out := render(t, `{
+		"grid": [{"top": "10%", "height": "40%"}, {"top": "60%", "height": "30%"}],
+		"xAxis": [{"type": "category", "data": ["1990s", "2000s"]}, {"type": "category", "gridIndex": 1, "data": ["1990s", "2000s"]}],
+		"yAxis": [{"type": "value"}, {"type": "value", "gridIndex": 1}],
+		"series": [
+			{"type": "bar", "data": [{"value": 3}, {"value": 4}]},
+			{"type": "line", "xAxisIndex": 1, "yAxisIndex": 1, "data": [{"value": 6}, {"value": 7}]}
+		]
+	}`)
+	if n := strings.Count(out, "<clipPath"); n != 2 {
+		t.Errorf("got %d panels, want 2", n)
+	}
+	bars := attrs(t, out, barRect)
+	line := attrs(t, out, `<path d="M[-\d.]+ ([-\d.]+) `)
+	if len(bars) != 2 || len(line) != 1 || bars[0][1]+bars[0][3] > 500*0.5+1 || line[0][0] < 500*0.6 {
+		t.Errorf("bars %v and line %v are not in their own panels", bars, line)
+	}
+}
+
+func TestPie(t *testing.T) {
+	out := render(t, `{"series": [{"type": "pie", "data": [
+		{"name": "Drama", "value": 6}, {"name": "Comedy", "value": 3}, {"name": "Action", "value": 1}, {"name": "None", "value": 0}]}]}`)
+	arcs := regexp.MustCompile(`<path d="M[-\d.]+ [-\d.]+ L[-\d.]+ [-\d.]+ A[-\d.]+ [-\d.]+ 0 (\d) 1 `).FindAllStringSubmatch(out, -1)
+	if len(arcs) != 3 {
+		t.Fatalf("got %d slices, want 3", len(arcs))
+	}
+	// only the slice over half the pie takes the large arc
+	if arcs[0][1] != "1" || arcs[1][1] != "0" || arcs[2][1] != "0" {
+		t.Errorf("large-arc flags %s %s %s, want 1 0 0", arcs[0][1], arcs[1][1], arcs[2][1])
+	}
+	if !strings.Contains(out, ">Drama: 6 (60.0%)<") {
+		t.Error("slice label lacks value and share")
+	}
+
+	out = render(t, `{"series": [{"type": "pie", "data": [{"name": "All", "value": 1}]}]}`)
+	if !strings.Contains(out, "<circle") {
+		t.Error("a single slice should be a full circle")
+	}
+	var b bytes.Buffer
+	if err := Render(&b, option(t, `{"series": [{"type": "pie", "data": [{"name": "None", "value": 0}]}]}`), 900, 500, testStyle); err == nil {
+		t.Error("pie without positive values rendered")
+	}
+}
+
+func TestWordCloud(t *testing.T) {
+	out := render(t, `{"series": [{"type": "wordCloud", "sizeRange": [14, 64], "data": [
+		{"name": "murder", "value": 100, "textStyle": {"color": "#ff0000"}},
+		{"name": "love", "value": 50},
+		{"name": "sequel", "value": 10},
+		{"name": "dystopia", "value": 5}]}]}`)
+	words := attrs(t, out, `<text x="([-\d.]+)" y="([-\d.]+)" font-size="(\d+)" fill="[^"]+" text-anchor="middle" dominant-baseline="central">`)
+	if len(words) != 4 {
+		t.Fatalf("placed %d words, want 4", len(words))
+	}
+	if words[0][2] != 64 || words[3][2] != 14 {
+		t.Errorf("font sizes %v, want 64 for the most and 14 for the least frequent", words)
+	}
+	if !strings.Contains(out, `fill="#ff0000" text-anchor="middle" dominant-baseline="central">murder`) {
+		t.Error("word color from textStyle is not used")
+	}
+	// estimated boxes, as the layout sees them, must not overlap
+	names := []string{"murder", "love", "sequel", "dystopia"}
+	box := func(i int) [4]float64 {
+		w := float64(len(names[i])) * words[i][2] * 0.58
+		return [4]float64{words[i][0] - w/2, words[i][1] - words[i][2]/2, words[i][0] + w/2, words[i][1] + words[i][2]/2}
+	}
+	for i := range words {
+		a := box(i)
+		if a[0] < 16 || a[2] > 900-16 || a[1] < 64 || a[3] > 500-16 {
+			t.Errorf("%s is outside the plot area", names[i])
+		}
+		for j := range i {
+			b := box(j)
+			if a[0] < b[2] && b[0] < a[2] && a[1] < b[3] && b[1] < a[3] {
+				t.Errorf("%s overlaps %s", names[i], names[j])
+			}
+		}
+	}
+}
+
+func TestSquarify(t *testing.T) {
+	r := rect{10, 20, 600, 400}
+	values := []float64{6, 0, 6, 4, 3, 2, 2, 1}
+	rects := squarify(values, r)
+	total := 24.0
+	for i, v := range values {
+		got := rects[i].w * rects[i].h
+		if want := v / total * r.w * r.h; math.Abs(got-want) > 1e-6*r.w*r.h {
+			t.Errorf("rect %d has area %.1f, want %.1f", i, got, want)
+		}
+		if v == 0 {
+			continue
+		}
+		if c := rects[i]; c.x < r.x-1e-9 || c.y < r.y-1e-9 || c.x+c.w > r.x+r.w+1e-9 || c.y+c.h > r.y+r.h+1e-9 {
+			t.Errorf("rect %d %v is outside %v", i, c, r)
+		}
+		for j := range i {
+			a, b := rects[i], rects[j]
+			if values[j] > 0 && a.x+1e-9 < b.x+b.w && b.x+1e-9 < a.x+a.w && a.y+1e-9 < b.y+b.h && b.y+1e-9 < a.y+a.h {
+				t.Errorf("rects %d and %d overlap", i, j)
+			}
+		}
+		// squarified rectangles stay far from slivers
+		if ratio := math.Max(rects[i].w/rects[i].h, rects[i].h/rects[i].w); ratio > 4 {
+			t.Errorf("rect %d has aspect ratio %.1f", i, ratio)
+		}
+	}
+	if len(squarify([]float64{0, 0}, r)) != 2 {
+		t.Error("squarify of zeros should still return one rect per value")
+	}
+}
+
+func TestTreemap(t *testing.T) {
+	out := render(t, `{"series": [{"type": "treemap", "data": [
+		{"name": "Original", "value": 30, "children": [
+			{"name": "English", "value": 20, "itemStyle": {"color": "#123456"}},
+			{"name": "French", "value": 10}]},
+		{"name": "Spoken", "value": 10, "children": [{"name": "German", "value": 10}]}]}]}`)
+	leaves := attrs(t, out, `<rect x="([-\d.]+)" y="([-\d.]+)" width="([-\d.]+)" height="([-\d.]+)" fill="[^"]+" stroke="#ffffff" stroke-width="1"><title>`)
+	if len(leaves) != 3 {
+		t.Fatalf("got %d languages, want 3", len(leaves))
+	}
+	if a, b := leaves[0][2]*leaves[0][3], leaves[1][2]*leaves[1][3]; math.Abs(a/b-2) > 0.1 {
+		t.Errorf("English is %.2f times French, want 2", a/b)
+	}
+	for _, s := range []string{">Original<", ">Spoken<", `fill="#123456"`, "<title>English: 20</title>"} {
+		if !strings.Contains(out, s) {
+			t.Errorf("SVG lacks %s", s)
+		}
+	}
+}
+
+func TestRenderMap(t *testing.T) {
+	shapes := Shapes{
+		"FR": {{{0, 40}, {10, 40}, {10, 50}, {0, 50}, {0, 40}}},
+		"DE": {{{10, 50}, {20, 50}, {20, 55}, {10, 55}, {10, 50}}, {{12, 51}, {13, 51}, {13, 52}, {12, 51}}},
+		"AQ": {{{0, -80}, {10, -80}, {10, -70}, {0, -80}}},
+	}
+	opt := option(t, `{
+		"visualMap": [{"min": 0, "max": 10, "inRange": {"color": ["#000000", "#ffffff"]}}],
+		"series": [{"type": "map", "data": [{"name": "FR", "value": 10}]}]
+	}`)
+	var b bytes.Buffer
+	if err := RenderMap(&b, opt, shapes, 900, 500, testStyle); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	if n := strings.Count(out, "<path"); n != 2 {
+		t.Errorf("drew %d regions, want FR and DE (Antarctica is cut off)", n)
+	}
+	if !strings.Contains(out, `fill="#ffffff" fill-rule="evenodd" stroke="#ffffff" stroke-width="0.5"><title>FR: 10</title>`) {
+		t.Error("FR is not shaded by its value")
+	}
+	if !strings.Contains(out, `fill="#e0e6f1" fill-rule="evenodd" stroke="#ffffff" stroke-width="0.5"><title>DE</title>`) {
+		t.Error("DE without data is not drawn in the grid color")
+	}
+	if de := regexp.MustCompile(`<path d="([^"]+)"[^>]*><title>DE`).FindStringSubmatch(out); de == nil || strings.Count(de[1], "M") != 2 {
+		t.Error("DE should be one path with its hole as a second ring")
+	}
+
+	if err := RenderMap(&b, option(t, `{"series": [{"type": "bar"}]}`), shapes, 900, 500, testStyle); err == nil {
+		t.Error("RenderMap of a bar chart succeeded")
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	for name, js := range map[string]string{
+		"no series":        `{"title": {"text": "Empty"}}`,
+		"unsupported type": `{"xAxis": [{"type": "category"}], "yAxis": [{}], "series": [{"type": "boxplot", "data": [[1, 2, 3, 4, 5]]}]}`,
+		"unsupported mix":  `{"xAxis": [{"type": "category"}], "yAxis": [{}], "series": [{"type": "bar", "data": [1]}, {"type": "candlestick", "data": [[1, 2, 3, 4]]}]}`,
+		"radar":            `{"radar": [{}], "series": [{"type": "radar", "data": [{"value": [1, 2, 3]}]}]}`,
+		"map via Render":   `{"series": [{"type": "map", "data": []}]}`,
+		"no axes":          `{"series": [{"type": "line", "data": [1, 2]}]}`,
+		"empty word cloud": `{"series": [{"type": "wordCloud", "data": []}]}`,
+		"empty treemap":    `{"series": [{"type": "treemap", "data": []}]}`,
+	} {
+		var b bytes.Buffer
+		if err := Render(&b, option(t, js), 900, 500, testStyle); err == nil {
+			t.Errorf("%s: Render succeeded, want an error", name)
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	for _, tc := range []struct {
+		v    float64
+		want string
+	}{
+		{0, "0"}, {7, "7"}, {6.25, "6.25"}, {150, "150"}, {12500, "12.5k"}, {3e6, "3M"}, {1.25e9, "1.25B"}, {-2e6, "-2M"},
+	} {
+		if got := formatNumber(tc.v); got != tc.want {
+			t.Errorf("formatNumber(%v) = %q, want %q", tc.v, got, tc.want)
+		}
+	}
+	for _, tc := range []struct{ raw, want float64 }{{0, 1}, {0.3, 0.5}, {1, 1}, {1.5, 2}, {3, 5}, {7, 10}, {120, 200}} {
+		if got := niceStep(tc.raw); got != tc.want {
+			t.Errorf("niceStep(%v) = %v, want %v", tc.raw, got, tc.want)
+		}
+	}
+	if got := Gradient([]string{"#000000", "#ffffff"}, 0.5); got != "#7f7f7f" {
+		t.Errorf("Gradient halfway = %s, want #7f7f7f", got)
+	}
+	if got := Gradient([]string{"#000000", "#ff0000", "#ffffff"}, 2); got != "#ffffff" {
+		t.Errorf("Gradient past the end = %s, want the last color", got)
+	}
+}
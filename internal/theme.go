@@ -0,0 +1,280 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"dv/internal/i18n"
+	"dv/internal/svg"
+)
+
+// Theme is the visual style applied to every chart and page of a run.
+type Theme struct {
+	Name       string   `json:"name"`
+	Background string   `json:"background"`
+	Text       string   `json:"text"`   // titles, legends, axis names
+	Subtle     string   `json:"subtle"` // axis lines and labels, subtitles
+	Grid       string   `json:"grid"`   // split lines
+	Font       string   `json:"font"`
+	Palette    []string `json:"palette"`   // series colors, in order
+	Diverging  []string `json:"diverging"` // continuous scales, low → high
+}
+
+var (
+	ThemeLight = Theme{
+		Name:       "light",
+		Background: "#ffffff",
+		Text:       "#333333",
+		Subtle:     "#6e7079",
+		Grid:       "#e0e6f1",
+		Font:       "sans-serif",
+		Palette:    []string{"#3498db", "#2ecc71", "#e67e22", "#e74c3c", "#9b59b6", "#1abc9c", "#f1c40f", "#34495e", "#95a5a6"},
+		Diverging:  []string{"#d73027", "#fee08b", "#1a9850"},
+	}
+	ThemeDark = Theme{
+		Name:       "dark",
+		Background: "#100c2a",
+		Text:       "#eeeeee",
+		Subtle:     "#b9b8ce",
+		Grid:       "#484753",
+		Font:       "sans-serif",
+		Palette:    []string{"#4992ff", "#7cffb2", "#fddd60", "#ff6e76", "#58d9f9", "#05c091", "#ff8a45", "#8d48e3", "#dd79ff"},
+		Diverging:  []string{"#ff6e76", "#fddd60", "#7cffb2"},
+	}
+	// ThemePrint stays readable on a monochrome printer.
+	ThemePrint = Theme{
+		Name:       "print",
+		Background: "#ffffff",
+		Text:       "#000000",
+		Subtle:     "#444444",
+		Grid:       "#d9d9d9",
+		Font:       "Georgia, serif",
+		Palette:    []string{"#252525", "#737373", "#bdbdbd", "#525252", "#969696", "#d9d9d9"},
+		Diverging:  []string{"#f0f0f0", "#969696", "#252525"},
+	}
+	// ThemeColorBlind uses the Okabe-Ito palette, distinguishable under the
+	// common forms of color vision deficiency, and an orange–blue scale.
+	ThemeColorBlind = Theme{
+		Name:       "colorblind",
+		Background: "#ffffff",
+		Text:       "#333333",
+		Subtle:     "#6e7079",
+		Grid:       "#e0e6f1",
+		Font:       "sans-serif",
+		Palette:    []string{"#0072b2", "#e69f00", "#009e73", "#cc79a7", "#56b4e9", "#d55e00", "#f0e442", "#000000"},
+		Diverging:  []string{"#d55e00", "#f7f7f7", "#0072b2"},
+	}
+)
+
+var builtinThemes = map[string]Theme{
+	ThemeLight.Name:      ThemeLight,
+	ThemeDark.Name:       ThemeDark,
+	ThemePrint.Name:      ThemePrint,
+	ThemeColorBlind.Name: ThemeColorBlind,
+}
+
+// ThemeNames lists the built-in themes.
+func ThemeNames() []string {
+	names := make([]string, 0, len(builtinThemes))
+	for name := range builtinThemes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func ParseTheme(name string) (Theme, error) {
+	if t, ok := builtinThemes[name]; ok {
+		return t, nil
+	}
+	return Theme{}, fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(ThemeNames(), ", "))
+}
+
+// LoadTheme reads a JSON theme definition. Fields left out are taken from the
+// built-in theme named by "base", or from the light theme.
+func LoadTheme(path string) (Theme, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Theme{}, err
+	}
+	var def struct {
+		Theme
+		Base string `json:"base"`
+	}
+	if err := json.Unmarshal(data, &def); err != nil {
+		return Theme{}, fmt.Errorf("failed to parse theme %s: %w", path, err)
+	}
+	base := ThemeLight
+	if def.Base != "" {
+		if base, err = ParseTheme(def.Base); err != nil {
+			return Theme{}, fmt.Errorf("theme %s: %w", path, err)
+		}
+	}
+	t := def.Theme
+	for _, f := range []struct {
+		dst *string
+		src string
+	}{
+		{&t.Background, base.Background},
+		{&t.Text, base.Text},
+		{&t.Subtle, base.Subtle},
+		{&t.Grid, base.Grid},
+		{&t.Font, base.Font},
+	} {
+		if *f.dst == "" {
+			*f.dst = f.src
+		}
+	}
+	if len(t.Palette) == 0 {
+		t.Palette = base.Palette
+	}
+	if len(t.Diverging) == 0 {
+		t.Diverging = base.Diverging
+	}
+	if len(t.Diverging) < 2 {
+		return Theme{}, fmt.Errorf("theme %s: diverging needs at least 2 colors", path)
+	}
+	if !fontFamilyRe.MatchString(t.Font) {
+		return Theme{}, fmt.Errorf("theme %s: font %q is not a CSS font-family list such as \"Segoe UI\", sans-serif", path, t.Font)
+	}
+	if t.Name == "" {
+		t.Name = path
+	}
+	return t, nil
+}
+
+// fontFamily is one quoted or plain font name, with nothing that could end the
+// CSS declaration or the style sheet.
+const fontFamily = `(?:"[^"\\;{}<>\n]+"|'[^'\\;{}<>\n]+'|[A-Za-z][\w -]*)`
+
+// fontFamilyRe matches a CSS font-family list.
+var fontFamilyRe = regexp.MustCompile(`^\s*` + fontFamily + `(?:\s*,\s*` + fontFamily + `)*\s*$`)
+
+// FontCSS returns Font for the page style sheets, which html/template would
+// otherwise blank out as soon as a family is quoted. A font LoadTheme would
+// have rejected falls back to sans-serif.
+func (t Theme) FontCSS() template.CSS {
+	if !fontFamilyRe.MatchString(t.Font) {
+		return "sans-serif"
+	}
+	return template.CSS(t.Font)
+}
+
+// WithTheme sets the colors, font and background of every chart and page.
+func WithTheme(t Theme) Option {
+	return func(c *Charts) {
+		c.theme = t
+	}
+}
+
+// Color returns the i-th palette color, wrapping around.
+func (t Theme) Color(i int) string {
+	return t.Palette[i%len(t.Palette)]
+}
+
+// Shade returns the Diverging color of v on a continuous scale from lo to hi.
+func (t Theme) Shade(v, lo, hi float64) string {
+	f := 0.0
+	if hi > lo {
+		f = (v - lo) / (hi - lo)
+	}
+	return svg.Gradient(t.Diverging, f)
+}
+
+func (t Theme) svgStyle(p *i18n.Printer) svg.Style {
+	return svg.Style{Background: t.Background, Text: t.Text, Subtle: t.Subtle, Grid: t.Grid, Font: t.Font, Number: p.Compact}
+}
+
+// themeVisitor restyles a chart's option right before it is serialized, so
+// charts don't need to pass theme options themselves. Colors and formatters a
+// chart sets explicitly win over the theme and locale.
+type themeVisitor struct {
+	charts.BaseConfigurationVisitor
+	theme         Theme
+	axisFormatter string // JS formatter for value axis labels
+}
+
+func (v themeVisitor) Visit(option map[string]interface{}) {
+	t := v.theme
+	option["color"] = t.Palette
+	option["backgroundColor"] = t.Background
+	option["textStyle"] = map[string]any{"color": t.Text, "fontFamily": t.Font}
+
+	styled := func(key string, style func(m map[string]any)) {
+		v, ok := option[key]
+		if !ok {
+			return
+		}
+		var generic any
+		if raw, err := json.Marshal(v); err != nil || json.Unmarshal(raw, &generic) != nil {
+			return
+		}
+		switch g := generic.(type) {
+		case map[string]any:
+			style(g)
+		case []any:
+			for _, e := range g {
+				if m, ok := e.(map[string]any); ok {
+					style(m)
+				}
+			}
+		}
+		option[key] = generic
+	}
+	styled("title", func(m map[string]any) {
+		setDefault(m, t.Text, "textStyle", "color")
+		setDefault(m, t.Subtle, "subtextStyle", "color")
+	})
+	styled("legend", func(m map[string]any) {
+		setDefault(m, t.Text, "textStyle", "color")
+	})
+	axis := func(m map[string]any) {
+		setDefault(m, t.Text, "nameTextStyle", "color")
+		setDefault(m, t.Subtle, "axisLabel", "color")
+		setDefault(m, t.Subtle, "axisLine", "lineStyle", "color")
+		setDefault(m, t.Grid, "splitLine", "lineStyle", "color")
+		if _, category := m["data"]; !category && m["type"] != "category" && v.axisFormatter != "" {
+			setDefault(m, opts.FuncOpts(v.axisFormatter), "axisLabel", "formatter")
+		}
+	}
+	styled("xAxis", axis)
+	styled("yAxis", axis)
+	styled("visualMap", func(m map[string]any) {
+		setDefault(m, t.Text, "textStyle", "color")
+	})
+}
+
+// setDefault sets m[path...] to value unless the chart already set it.
+func setDefault(m map[string]any, value any, path ...string) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := m[key].(map[string]any)
+		if !ok {
+			next = map[string]any{}
+			m[key] = next
+		}
+		m = next
+	}
+	if _, ok := m[path[len(path)-1]]; !ok {
+		m[path[len(path)-1]] = value
+	}
+}
+
+// themeable is implemented by every go-echarts chart through its embedded
+// BaseConfiguration.
+type themeable interface {
+	Accept(visitor charts.ConfigurationVisitor)
+}
+
+func (c *Charts) applyTheme(chart ChartRenderer) {
+	if t, ok := chart.(themeable); ok {
+		t.Accept(themeVisitor{theme: c.theme, axisFormatter: c.printer.AxisFormatterJS()})
+	}
+}
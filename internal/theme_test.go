@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLoadThemeFont(t *testing.T) {
+	for _, tc := range []struct {
+		font string
+		ok   bool
+	}{
+		{`"Segoe UI", sans-serif`, true},
+		{`'Open Sans', Arial`, true},
+		{`Georgia, "Times New Roman", Times, serif`, true},
+		{`system-ui`, true},
+		{`Arial; background: url(x)`, false},
+		{`"Arial</style><script>alert(1)</script>"`, false},
+		{`"Broken`, false},
+		{`Arial,`, false},
+	} {
+		path := filepath.Join(t.TempDir(), "theme.json")
+		body := `{"font": ` + strconv.Quote(tc.font) + `}`
+		if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		theme, err := LoadTheme(path)
+		if (err == nil) != tc.ok {
+			t.Errorf("LoadTheme with font %s = %v, want ok %v", tc.font, err, tc.ok)
+			continue
+		}
+		if tc.ok && string(theme.FontCSS()) != tc.font {
+			t.Errorf("FontCSS = %s, want %s", theme.FontCSS(), tc.font)
+		}
+	}
+	if got := (Theme{Font: "x; y"}).FontCSS(); got != "sans-serif" {
+		t.Errorf("FontCSS of an invalid font = %s, want sans-serif", got)
+	}
+}
+
+func TestPagesQuotedFont(t *testing.T) {
+	theme := ThemeLight
+	theme.Font = `"Segoe UI", 'Open Sans', sans-serif`
+	c := NewCharts(nil, t.TempDir()+"/", WithTheme(theme))
+	for _, page := range []string{dashboardFile, indexFile} {
+		var buf bytes.Buffer
+		if err := c.executeTemplate(&buf, page, c.dashboardView(&RunReport{})); err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Contains(buf.Bytes(), []byte("ZgotmplZ")) || !bytes.Contains(buf.Bytes(), []byte("font-family: "+theme.Font+";")) {
+			t.Errorf("%s does not set font-family: %s", page, theme.Font)
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+
+	"dv/internal/assets"
+	"dv/internal/svg"
+)
+
+const worldMapFile = "world_map.html"
+
+// countriesAsset is the vendored Natural Earth GeoJSON; the map is drawn from
+// it, never from a CDN.
+const countriesAsset = "geo/countries.geojson"
+
+type worldMapChart struct{}
+
+func init() { Register(worldMapChart{}) }
+
+func (worldMapChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "map",
+		Description: "Film production by country on a world map (movies, budget, revenue, rating)",
+		ChartType:   "Map",
+		Source:      "CountryProductionStats",
+		Output:      worldMapFile,
+	}
+}
+
+func (worldMapChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.WorldMapWithCount(ctx)
+}
+
+// countryMetric is one of the values the map can be shaded by.
+type countryMetric struct {
+	Label string `json:"label"`
+	Money bool   `json:"money"`
+}
+
+// countryRow is a country as the page script sees it: its ISO code, which the
+// GeoJSON regions are named by, and the values of every metric.
+type countryRow struct {
+	Code    string    `json:"code"`
+	Country string    `json:"country"`
+	Values  []float64 `json:"values"`
+}
+
+// worldMapScript registers the boundaries, adds the metric selector above the
+// chart and shades the map by the selected metric; the tooltip shows all of
+// them.
+const worldMapScript = `(function () {
+	var chart = %%MY_ECHARTS%%;
+	var rows = %s, metrics = %s, money = %s, number = %s, esc = %s;
+	echarts.registerMap('countries', %s);
+	var byCode = {};
+	rows.forEach(function (r) { byCode[r.code] = r; });
+	function format(i, v) { return metrics[i].money ? money(v) : number(v); }
+	function show(i) {
+		var values = rows.map(function (r) { return r.values[i]; });
+		chart.setOption({
+			visualMap: {min: Math.min.apply(null, values), max: Math.max.apply(null, values), text: [metrics[i].label, ''], formatter: function (v) { return format(i, v); }},
+			series: [{type: 'map', map: 'countries', name: metrics[i].label, roam: true, emphasis: {label: {show: false}},
+				data: rows.map(function (r) { return {name: r.code, value: r.values[i]}; })}]
+		});
+	}
+	chart.setOption({tooltip: {trigger: 'item', formatter: function (p) {
+		var r = byCode[p.name];
+		if (!r) { return esc(p.name); }
+		return '<b>' + esc(r.country) + '</b>' + metrics.map(function (m, i) { return '<br/>' + esc(m.label) + ': ' + format(i, r.values[i]); }).join('');
+	}}});
+	var select = document.createElement('select');
+	select.style.margin = '8px';
+	metrics.forEach(function (m, i) {
+		var o = document.createElement('option');
+		o.value = i;
+		o.textContent = m.label;
+		select.appendChild(o);
+	});
+	select.onchange = function () { show(+select.value); };
+	chart.getDom().parentNode.insertBefore(select, chart.getDom());
+	show(0);
+})();`
+
+// WorldMapWithCount shades each producing country by movie count, average
+// budget, average revenue or average rating; the page switches between them.
+// Countries are matched to their boundaries by ISO code, so the GeoJSON is
+// embedded in the page and the map works offline. go-echarts can only load
+// its preset name-keyed maps, so the map series is set from the page script.
+func (c *Charts) WorldMapWithCount(ctx context.Context) (int, error) {
+	world, err := loadCountries()
+	if err != nil {
+		return 0, err
+	}
+	data, err := c.repo.CountryProductionStats(ctx, c.filter.CountryProductionStatsParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get country production stats: %w", err)
+	}
+	rows := make([]countryRow, 0, len(data))
+	for _, d := range data {
+		code := strings.ToUpper(d.CountryIsoCode.String)
+		if _, ok := world.shapes[code]; !ok {
+			continue
+		}
+		rows = append(rows, countryRow{
+			Code:    code,
+			Country: d.CountryName.String,
+			Values:  []float64{float64(d.MoviesCount), d.AvgBudget, d.AvgRevenue, d.AvgRating},
+		})
+	}
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("no countries to map")
+	}
+	metrics := []countryMetric{
+		{Label: c.printer.T("Movies")},
+		{Label: c.printer.T("Avg Budget"), Money: true},
+		{Label: c.printer.T("Avg Revenue"), Money: true},
+		{Label: c.printer.T("Avg Rating")},
+	}
+	lo, hi := rows[0].Values[0], rows[0].Values[0]
+	for _, r := range rows {
+		lo, hi = min(lo, r.Values[0]), max(hi, r.Values[0])
+	}
+
+	m := &worldMap{Map: charts.NewMap(), shapes: world.shapes}
+	m.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{
+			Title:    c.printer.T("Film Production by Country"),
+			Subtitle: c.printer.T("countries=%d", len(rows)),
+		}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true), Trigger: "item"}),
+		charts.WithVisualMapOpts(opts.VisualMap{
+			Type:       "continuous",
+			Calculable: opts.Bool(true),
+			Min:        float32(lo),
+			Max:        float32(hi),
+			Text:       []string{metrics[0].Label, ""},
+			Right:      "2%",
+			Top:        "middle",
+			InRange:    &opts.VisualMapInRange{Color: c.theme.Diverging},
+		}),
+		charts.WithInitializationOpts(opts.Initialization{Height: "560px"}),
+	)
+	if c.format == FormatSVG {
+		// the image has no page script, so it shows the first metric
+		items := make([]opts.MapData, 0, len(rows))
+		for _, r := range rows {
+			items = append(items, opts.MapData{Name: r.Code, Value: r.Values[0]})
+		}
+		m.AddSeries(metrics[0].Label, items)
+		return len(data), c.render(m, worldMapFile)
+	}
+
+	rowsJSON, err := json.Marshal(rows)
+	if err != nil {
+		return 0, err
+	}
+	metricsJSON, err := json.Marshal(metrics)
+	if err != nil {
+		return 0, err
+	}
+	m.AddJSFuncs(fmt.Sprintf(worldMapScript, rowsJSON, metricsJSON, c.printer.MoneyFormatterJS(), c.printer.NumberFormatterJS(""), escapeHTMLJS, world.geoJSON))
+	return len(data), c.render(m, worldMapFile)
+}
+
+// worldMap is a map chart that carries its region outlines, so it can also be
+// drawn as SVG.
+type worldMap struct {
+	*charts.Map
+	shapes svg.Shapes
+}
+
+type countryMap struct {
+	geoJSON []byte // FeatureCollection with each region named by ISO code
+	shapes  svg.Shapes
+}
+
+var (
+	countriesOnce sync.Once
+	countries     *countryMap
+	countriesErr  error
+)
+
+// loadCountries parses the vendored boundaries once per process.
+func loadCountries() (*countryMap, error) {
+	countriesOnce.Do(func() {
+		raw, err := assets.Read(countriesAsset)
+		if err != nil {
+			countriesErr = err
+			return
+		}
+		countries, countriesErr = parseCountries(raw)
+	})
+	return countries, countriesErr
+}
+
+// parseCountries renames every feature of a Natural Earth admin-0 GeoJSON to
+// its ISO 3166-1 alpha-2 code and keeps only the geometry; features without a
+// code (disputed areas) are dropped.
+func parseCountries(raw []byte) (*countryMap, error) {
+	var fc struct {
+		Features []struct {
+			Properties map[string]any `json:"properties"`
+			Geometry   struct {
+				Type        string          `json:"type"`
+				Coordinates json.RawMessage `json:"coordinates"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.NewDecoder(bytes.NewReader(raw)).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", countriesAsset, err)
+	}
+	type feature struct {
+		Type       string            `json:"type"`
+		Properties map[string]string `json:"properties"`
+		Geometry   any               `json:"geometry"`
+	}
+	out := struct {
+		Type     string    `json:"type"`
+		Features []feature `json:"features"`
+	}{Type: "FeatureCollection"}
+	shapes := make(svg.Shapes)
+	for _, f := range fc.Features {
+		code := isoCode(f.Properties)
+		if code == "" {
+			continue
+		}
+		var polygons [][][][2]float64
+		switch f.Geometry.Type {
+		case "Polygon":
+			var p [][][2]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &p); err != nil {
+				return nil, fmt.Errorf("failed to decode %s geometry: %w", code, err)
+			}
+			polygons = append(polygons, p)
+		case "MultiPolygon":
+			if err := json.Unmarshal(f.Geometry.Coordinates, &polygons); err != nil {
+				return nil, fmt.Errorf("failed to decode %s geometry: %w", code, err)
+			}
+		default:
+			continue
+		}
+		for _, p := range polygons {
+			shapes[code] = append(shapes[code], p...)
+		}
+		out.Features = append(out.Features, feature{Type: "Feature", Properties: map[string]string{"name": code}, Geometry: f.Geometry})
+	}
+	if len(shapes) == 0 {
+		return nil, fmt.Errorf("%s has no country boundaries", countriesAsset)
+	}
+	geoJSON, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	return &countryMap{geoJSON: geoJSON, shapes: shapes}, nil
+}
+
+// isoCode prefers ISO_A2_EH, which Natural Earth fills in where ISO_A2 is
+// "-99" (France, Norway).
+func isoCode(props map[string]any) string {
+	for _, key := range []string{"ISO_A2_EH", "ISO_A2", "iso_a2"} {
+		if s, ok := props[key].(string); ok && len(s) == 2 {
+			return strings.ToUpper(s)
+		}
+	}
+	return ""
+}
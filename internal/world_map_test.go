@@ -0,0 +1,151 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"dv/db"
+)
+
+func TestISOCode(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		props map[string]any
+		want  string
+	}{
+		{"ISO_A2", map[string]any{"ISO_A2": "DE"}, "DE"},
+		{"ISO_A2_EH wins", map[string]any{"ISO_A2_EH": "FR", "ISO_A2": "-99"}, "FR"},
+		{"-99 falls back", map[string]any{"ISO_A2_EH": "-99", "ISO_A2": "NO"}, "NO"},
+		{"only -99", map[string]any{"ISO_A2_EH": "-99", "ISO_A2": "-99"}, ""},
+		{"lower-case key and value", map[string]any{"iso_a2": "jp"}, "JP"},
+		{"not a string", map[string]any{"ISO_A2": 76}, ""},
+		{"no code", map[string]any{"NAME": "Somaliland"}, ""},
+	} {
+		if got := isoCode(tc.props); got != tc.want {
+			t.Errorf("%s: isoCode(%v) = %q, want %q", tc.name, tc.props, got, tc.want)
+		}
+	}
+}
+
+const testCountries = `{"type": "FeatureCollection", "features": [
+	{"type": "Feature", "properties": {"ISO_A2": "-99", "ISO_A2_EH": "FR", "NAME": "France"},
+	 "geometry": {"type": "MultiPolygon", "coordinates": [
+		[[[2, 51], [8, 49], [7, 44], [-1, 43], [2, 51]]],
+		[[[9, 43], [10, 43], [9, 41], [9, 43]]]]}},
+	{"type": "Feature", "properties": {"ISO_A2": "LU"},
+	 "geometry": {"type": "Polygon", "coordinates": [[[6, 50], [6.5, 50], [6.2, 49.5], [6, 50]], [[6.1, 49.9], [6.2, 49.9], [6.1, 49.8], [6.1, 49.9]]]}},
+	{"type": "Feature", "properties": {"ISO_A2": "-99", "NAME": "N. Cyprus"},
+	 "geometry": {"type": "Polygon", "coordinates": [[[33, 35], [34, 35], [33.5, 35.5], [33, 35]]]}},
+	{"type": "Feature", "properties": {"ISO_A2": "VA"},
+	 "geometry": {"type": "Point", "coordinates": [12.45, 41.9]}}
+]}`
+
+func TestParseCountries(t *testing.T) {
+	m, err := parseCountries([]byte(testCountries))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(m.shapes); got != 2 {
+		t.Errorf("parsed %d countries, want FR and LU", got)
+	}
+	// every ring of a MultiPolygon, including holes of a Polygon
+	if got := len(m.shapes["FR"]); got != 2 {
+		t.Errorf("FR has %d rings, want 2", got)
+	}
+	if got := len(m.shapes["LU"]); got != 2 {
+		t.Errorf("LU has %d rings, want 2", got)
+	}
+	if got := m.shapes["FR"][1][2]; got != [2]float64{9, 41} {
+		t.Errorf("FR ring 1 point 2 = %v, want [9 41]", got)
+	}
+
+	var fc struct {
+		Type     string `json:"type"`
+		Features []struct {
+			Properties map[string]string `json:"properties"`
+			Geometry   struct {
+				Type string `json:"type"`
+			} `json:"geometry"`
+		} `json:"features"`
+	}
+	if err := json.Unmarshal(m.geoJSON, &fc); err != nil {
+		t.Fatal(err)
+	}
+	var names, types []string
+	for _, f := range fc.Features {
+		names = append(names, f.Properties["name"])
+		types = append(types, f.Geometry.Type)
+		if len(f.Properties) != 1 {
+			t.Errorf("feature %s keeps properties %v, want only its name", f.Properties["name"], f.Properties)
+		}
+	}
+	if fc.Type != "FeatureCollection" || !slices.Equal(names, []string{"FR", "LU"}) || !slices.Equal(types, []string{"MultiPolygon", "Polygon"}) {
+		t.Errorf("GeoJSON is %s of %v %v, want FeatureCollection of [FR LU] [MultiPolygon Polygon]", fc.Type, names, types)
+	}
+}
+
+func TestParseCountriesErrors(t *testing.T) {
+	for name, raw := range map[string]string{
+		"bad JSON":      `{"features": [`,
+		"no countries":  `{"type": "FeatureCollection", "features": [{"properties": {"ISO_A2": "-99"}, "geometry": {"type": "Polygon", "coordinates": []}}]}`,
+		"bad polygon":   `{"features": [{"properties": {"ISO_A2": "DE"}, "geometry": {"type": "Polygon", "coordinates": [1, 2]}}]}`,
+		"bad multipart": `{"features": [{"properties": {"ISO_A2": "DE"}, "geometry": {"type": "MultiPolygon", "coordinates": [[1]]}}]}`,
+	} {
+		if _, err := parseCountries([]byte(raw)); err == nil {
+			t.Errorf("%s: parseCountries succeeded, want an error", name)
+		}
+	}
+}
+
+func TestVendoredCountries(t *testing.T) {
+	m, err := loadCountries()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Natural Earth 1:110m: 177 features, three without a code
+	if got := len(m.shapes); got != 174 {
+		t.Errorf("vendored boundaries have %d countries, want 174", got)
+	}
+	for _, code := range []string{"FR", "NO", "US", "RU", "BR"} {
+		if len(m.shapes[code]) == 0 {
+			t.Errorf("vendored boundaries have no %s", code)
+		}
+	}
+	if info, _ := DefaultRegistry().Info("map"); info.Disabled {
+		t.Error("map is disabled by default")
+	}
+}
+
+func TestWorldMap(t *testing.T) {
+	repo, err := db.LoadFixtures("../fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, format := range []Format{FormatHTML, FormatSVG} {
+		dir := t.TempDir() + "/"
+		c := NewCharts(repo, dir, WithFormat(format), WithDashboard(false))
+		if err := c.Generate(context.Background(), "map"); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		out, err := os.ReadFile(filepath.Join(dir, c.outputName(worldMapFile)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "echarts.registerMap('countries'"
+		if format == FormatSVG {
+			want = "<path"
+		}
+		if !bytes.Contains(out, []byte(want)) || !bytes.Contains(out, []byte("FR")) {
+			t.Errorf("%s map has no France region", format)
+		}
+		// country names and labels are data to the tooltip, not markup
+		if format == FormatHTML && !bytes.Contains(out, []byte("esc(r.country)")) {
+			t.Error("the tooltip does not escape country names")
+		}
+	}
+}
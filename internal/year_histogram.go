@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-echarts/go-echarts/v2/charts"
+	"github.com/go-echarts/go-echarts/v2/opts"
+)
+
+const yearHistogramFile = "movies_year_histogram.html"
+
+type yearHistogramChart struct{}
+
+func init() { Register(yearHistogramChart{}) }
+
+func (yearHistogramChart) Info() ChartInfo {
+	return ChartInfo{
+		Name:        "hist",
+		Description: "Number of movies by release year (output volume over time)",
+		ChartType:   "Histogram",
+		Source:      "YearlyTrends",
+		Output:      yearHistogramFile,
+	}
+}
+
+func (yearHistogramChart) Generate(ctx context.Context, c *Charts) (int, error) {
+	return c.MovieYearHistogramWithCount(ctx)
+}
+
+// MovieYearHistogramWithCount builds a histogram-like bar chart of movie counts per release year
+// using the YearlyTrends query (already filtered) and presents contiguous years on a numeric axis.
+func (c *Charts) MovieYearHistogramWithCount(ctx context.Context) (int, error) {
+	data, err := c.repo.YearlyTrends(ctx, c.filter.YearlyTrendsParams())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get yearly trends: %w", err)
+	}
+	if len(data) == 0 {
+		return 0, fmt.Errorf("no yearly data")
+	}
+
+	// Build map year->count then fill gaps
+	yearMap := make(map[int]int64, len(data))
+	minYear := int(data[0].Year)
+	maxYear := int(data[0].Year)
+	total := int64(0)
+	maxCount := int64(0)
+	for _, r := range data {
+		y := int(r.Year)
+		yearMap[y] = r.MoviesCount
+		total += r.MoviesCount
+		if r.MoviesCount > maxCount {
+			maxCount = r.MoviesCount
+		}
+		if y < minYear {
+			minYear = y
+		}
+		if y > maxYear {
+			maxYear = y
+		}
+	}
+	spanYears := maxYear - minYear + 1
+	xs := make([]interface{}, 0, spanYears)
+	bars := make([]opts.BarData, 0, spanYears)
+	for y := minYear; y <= maxYear; y++ {
+		cnt := yearMap[y]
+		xs = append(xs, y)
+		bars = append(bars, opts.BarData{Value: cnt})
+	}
+	avgPerYear := float64(total) / float64(spanYears)
+
+	bar := charts.NewBar()
+	bar.SetGlobalOptions(
+		charts.WithTitleOpts(opts.Title{Title: c.printer.T("Movies Released per Year"), Subtitle: c.printer.T("years=%d(%d-%d) total=%s avg≈%s max=%s", spanYears, minYear, maxYear, c.printer.Int(total), c.printer.Number(avgPerYear, 1), c.printer.Int(maxCount))}),
+		charts.WithTooltipOpts(opts.Tooltip{Show: opts.Bool(true)}),
+		charts.WithLegendOpts(opts.Legend{Show: opts.Bool(true)}),
+		charts.WithXAxisOpts(opts.XAxis{Name: c.printer.T("Year"), Type: "category"}),
+		charts.WithYAxisOpts(opts.YAxis{Name: c.printer.T("Movies")}),
+	)
+	bar.SetXAxis(xs).AddSeries(c.printer.T("Movies"), bars).SetSeriesOptions(
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+		charts.WithBarChartOpts(opts.BarChart{BarCategoryGap: "0%", BarGap: "0%"}),
+		charts.WithMarkLineNameTypeItemOpts(opts.MarkLineNameTypeItem{Name: c.printer.T("Avg"), Type: "average"}),
+	)
+
+	// Trend line over all years (including filled zeros)
+	trend := make([]opts.LineData, 0, len(bars))
+	for _, b := range bars {
+		trend = append(trend, opts.LineData{Value: b.Value})
+	}
+	line := charts.NewLine()
+	line.AddSeries(c.printer.T("Trend"), trend).SetSeriesOptions(
+		charts.WithLineChartOpts(opts.LineChart{Smooth: opts.Bool(true)}),
+		charts.WithLabelOpts(opts.Label{Show: opts.Bool(false)}),
+	)
+	bar.Overlap(line)
+	return spanYears, c.render(bar, yearHistogramFile)
+}
@@ -0,0 +1,84 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Pearson is the linear correlation coefficient of xs and ys.
+func Pearson(xs, ys []float64) float64 {
+	mustSameLen(xs, ys)
+	if len(xs) < 2 {
+		return 0
+	}
+	mx, my := Mean(xs), Mean(ys)
+	var sxy, sxx, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-mx, ys[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// Spearman is the rank correlation coefficient; tied values get their
+// average rank.
+func Spearman(xs, ys []float64) float64 {
+	mustSameLen(xs, ys)
+	return Pearson(Ranks(xs), Ranks(ys))
+}
+
+// Ranks returns 1-based ranks of xs, averaging ranks of ties.
+func Ranks(xs []float64) []float64 {
+	idx := make([]int, len(xs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return xs[idx[a]] < xs[idx[b]] })
+	ranks := make([]float64, len(xs))
+	for i := 0; i < len(idx); {
+		j := i + 1
+		for j < len(idx) && xs[idx[j]] == xs[idx[i]] {
+			j++
+		}
+		r := float64(i+j+1) / 2 // average of ranks i+1..j
+		for k := i; k < j; k++ {
+			ranks[idx[k]] = r
+		}
+		i = j
+	}
+	return ranks
+}
+
+// LinearFit is an ordinary least squares fit y = Intercept + Slope·x.
+type LinearFit struct {
+	Slope     float64
+	Intercept float64
+	R2        float64
+}
+
+func (f LinearFit) Predict(x float64) float64 { return f.Intercept + f.Slope*x }
+
+func LinearRegression(xs, ys []float64) LinearFit {
+	mustSameLen(xs, ys)
+	if len(xs) < 2 {
+		return LinearFit{}
+	}
+	mx, my := Mean(xs), Mean(ys)
+	var sxy, sxx float64
+	for i := range xs {
+		dx := xs[i] - mx
+		sxy += dx * (ys[i] - my)
+		sxx += dx * dx
+	}
+	if sxx == 0 {
+		return LinearFit{Intercept: my}
+	}
+	slope := sxy / sxx
+	r := Pearson(xs, ys)
+	return LinearFit{Slope: slope, Intercept: my - slope*mx, R2: r * r}
+}
@@ -0,0 +1,67 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Interval is a confidence interval around Estimate.
+type Interval struct {
+	Estimate float64
+	Lo       float64
+	Hi       float64
+}
+
+// Bootstrap computes a percentile bootstrap confidence interval for stat over
+// xs using the given number of resamples (default 1000) and confidence level
+// (e.g. 0.95). Pass a seeded rng for reproducible intervals; nil uses a random
+// seed.
+func Bootstrap(xs []float64, stat func([]float64) float64, resamples int, confidence float64, rng *rand.Rand) Interval {
+	if len(xs) == 0 {
+		return Interval{}
+	}
+	if resamples <= 0 {
+		resamples = 1000
+	}
+	if rng == nil {
+		rng = rand.New(rand.NewSource(rand.Int63()))
+	}
+	estimates := make([]float64, resamples)
+	buf := make([]float64, len(xs))
+	for i := range estimates {
+		for j := range buf {
+			buf[j] = xs[rng.Intn(len(xs))]
+		}
+		estimates[i] = stat(buf)
+	}
+	s := NewSample(estimates)
+	alpha := (1 - confidence) / 2
+	return Interval{Estimate: stat(xs), Lo: s.Quantile(alpha), Hi: s.Quantile(1 - alpha)}
+}
+
+// SilvermanBandwidth is Silverman's rule-of-thumb bandwidth for a Gaussian
+// kernel density estimate.
+func SilvermanBandwidth(xs []float64) float64 {
+	sd := StdDev(xs)
+	spread := sd
+	if iqr := IQR(xs) / 1.34; iqr > 0 && iqr < spread {
+		spread = iqr
+	}
+	if spread <= 0 {
+		return 1
+	}
+	return 0.9 * spread * math.Pow(float64(len(xs)), -0.2)
+}
+
+// GaussianKDE evaluates the Gaussian kernel density estimate of xs at x.
+func GaussianKDE(xs []float64, bandwidth, x float64) float64 {
+	if len(xs) == 0 || bandwidth <= 0 {
+		return 0
+	}
+	s := 0.0
+	for _, v := range xs {
+		u := (x - v) / bandwidth
+		s += math.Exp(-0.5 * u * u)
+	}
+	return s / (float64(len(xs)) * bandwidth * math.Sqrt(2*math.Pi))
+}
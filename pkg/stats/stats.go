@@ -0,0 +1,163 @@
+// Package stats implements the descriptive statistics shared by chart code and
+// reports. Statistics of empty (or too short) inputs are 0, and functions taking
+// two slices panic when their lengths differ.
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// Sample is a sorted copy of a data set, so repeated quantile queries do not
+// re-sort.
+type Sample struct {
+	sorted []float64
+}
+
+// NewSample copies and sorts xs.
+func NewSample(xs []float64) *Sample {
+	cp := append([]float64(nil), xs...)
+	sort.Float64s(cp)
+	return &Sample{sorted: cp}
+}
+
+func (s *Sample) Len() int { return len(s.sorted) }
+
+// Values returns the sorted data; callers must not modify it.
+func (s *Sample) Values() []float64 { return s.sorted }
+
+func (s *Sample) Min() float64 {
+	if len(s.sorted) == 0 {
+		return 0
+	}
+	return s.sorted[0]
+}
+
+func (s *Sample) Max() float64 {
+	if len(s.sorted) == 0 {
+		return 0
+	}
+	return s.sorted[len(s.sorted)-1]
+}
+
+// Quantile interpolates linearly between closest ranks (type 7, the default
+// of R and numpy).
+func (s *Sample) Quantile(q float64) float64 {
+	return sortedQuantile(s.sorted, q)
+}
+
+func (s *Sample) Median() float64 { return s.Quantile(0.5) }
+
+func (s *Sample) IQR() float64 { return s.Quantile(0.75) - s.Quantile(0.25) }
+
+// Clip keeps the values between the lo and hi quantiles inclusive. lo <= 0
+// and hi >= 1 return s itself.
+func (s *Sample) Clip(lo, hi float64) *Sample {
+	if lo <= 0 && hi >= 1 {
+		return s
+	}
+	l, h := s.Quantile(lo), s.Quantile(hi)
+	i := sort.SearchFloat64s(s.sorted, l)
+	j := sort.Search(len(s.sorted), func(k int) bool { return s.sorted[k] > h })
+	return &Sample{sorted: s.sorted[i:j]}
+}
+
+// Quantile sorts a copy of xs; use a Sample for repeated queries.
+func Quantile(xs []float64, q float64) float64 {
+	return NewSample(xs).Quantile(q)
+}
+
+func Median(xs []float64) float64 { return Quantile(xs, 0.5) }
+
+func IQR(xs []float64) float64 { return NewSample(xs).IQR() }
+
+func sortedQuantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	if q <= 0 {
+		return sorted[0]
+	}
+	if q >= 1 {
+		return sorted[len(sorted)-1]
+	}
+	pos := q * float64(len(sorted)-1)
+	i := int(pos)
+	frac := pos - float64(i)
+	if i+1 < len(sorted) {
+		return sorted[i] + (sorted[i+1]-sorted[i])*frac
+	}
+	return sorted[i]
+}
+
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return 0
+	}
+	s := 0.0
+	for _, v := range xs {
+		s += v
+	}
+	return s / float64(len(xs))
+}
+
+func MinMax(xs []float64) (float64, float64) {
+	if len(xs) == 0 {
+		return 0, 0
+	}
+	lo, hi := xs[0], xs[0]
+	for _, v := range xs[1:] {
+		lo = math.Min(lo, v)
+		hi = math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// Variance is the unbiased sample variance (n-1 denominator).
+func Variance(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	m := Mean(xs)
+	s := 0.0
+	for _, v := range xs {
+		s += (v - m) * (v - m)
+	}
+	return s / float64(len(xs)-1)
+}
+
+func StdDev(xs []float64) float64 { return math.Sqrt(Variance(xs)) }
+
+// Skewness is the moment coefficient of skewness g1 = m3 / m2^1.5.
+func Skewness(xs []float64) float64 {
+	m2, m3, _ := centralMoments(xs)
+	if m2 == 0 {
+		return 0
+	}
+	return m3 / math.Pow(m2, 1.5)
+}
+
+// Kurtosis is the excess kurtosis g2 = m4 / m2² - 3 (0 for a normal distribution).
+func Kurtosis(xs []float64) float64 {
+	m2, _, m4 := centralMoments(xs)
+	if m2 == 0 {
+		return 0
+	}
+	return m4/(m2*m2) - 3
+}
+
+func centralMoments(xs []float64) (m2, m3, m4 float64) {
+	if len(xs) == 0 {
+		return 0, 0, 0
+	}
+	m := Mean(xs)
+	for _, v := range xs {
+		d := v - m
+		d2 := d * d
+		m2 += d2
+		m3 += d2 * d
+		m4 += d2 * d2
+	}
+	n := float64(len(xs))
+	return m2 / n, m3 / n, m4 / n
+}
@@ -0,0 +1,143 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+const eps = 1e-9
+
+func approx(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > eps {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestDescriptive(t *testing.T) {
+	xs := []float64{2, 4, 4, 4, 5, 5, 7, 9}
+	approx(t, "Mean", Mean(xs), 5)
+	approx(t, "Variance", Variance(xs), 32.0/7)
+	approx(t, "StdDev", StdDev(xs), math.Sqrt(32.0/7))
+	approx(t, "Median", Median(xs), 4.5)
+	approx(t, "IQR", IQR(xs), 5.5-4)
+	lo, hi := MinMax(xs)
+	approx(t, "Min", lo, 2)
+	approx(t, "Max", hi, 9)
+
+	approx(t, "Mean(empty)", Mean(nil), 0)
+	approx(t, "Variance(one)", Variance([]float64{3}), 0)
+	approx(t, "Quantile(empty)", Quantile(nil, 0.5), 0)
+}
+
+func TestSampleQuantile(t *testing.T) {
+	s := NewSample([]float64{5, 1, 4, 2, 3})
+	for _, tc := range []struct{ q, want float64 }{
+		{0, 1}, {0.25, 2}, {0.5, 3}, {0.9, 4.6}, {1, 5}, {-1, 1}, {2, 5},
+	} {
+		approx(t, "Quantile", s.Quantile(tc.q), tc.want)
+	}
+	clipped := s.Clip(0.25, 0.75)
+	if got := clipped.Values(); len(got) != 3 || got[0] != 2 || got[2] != 4 {
+		t.Errorf("Clip(0.25, 0.75) = %v, want [2 3 4]", got)
+	}
+	if s.Clip(0, 1) != s {
+		t.Error("Clip(0, 1) should return the sample itself")
+	}
+}
+
+func TestShape(t *testing.T) {
+	symmetric := []float64{1, 2, 3, 4, 5}
+	approx(t, "Skewness(symmetric)", Skewness(symmetric), 0)
+	approx(t, "Kurtosis(uniform 1..5)", Kurtosis(symmetric), -1.3)
+	if Skewness([]float64{1, 1, 1, 1, 10}) <= 0 {
+		t.Error("right tail should give positive skewness")
+	}
+	approx(t, "Skewness(constant)", Skewness([]float64{3, 3, 3}), 0)
+}
+
+func TestWeighted(t *testing.T) {
+	xs := []float64{1, 2, 3}
+	ws := []float64{1, 1, 2}
+	expanded := []float64{1, 2, 3, 3}
+	approx(t, "WeightedMean", WeightedMean(xs, ws), Mean(expanded))
+	approx(t, "WeightedVariance", WeightedVariance(xs, ws), Variance(expanded))
+	approx(t, "WeightedQuantile", WeightedQuantile(xs, ws, 0.5), 2)
+	approx(t, "WeightedQuantile", WeightedQuantile(xs, ws, 0.9), 3)
+
+	defer func() {
+		if recover() == nil {
+			t.Error("length mismatch should panic")
+		}
+	}()
+	WeightedMean(xs, ws[:2])
+}
+
+func TestCorrelation(t *testing.T) {
+	xs := []float64{1, 2, 3, 4, 5}
+	approx(t, "Pearson(linear)", Pearson(xs, []float64{2, 4, 6, 8, 10}), 1)
+	approx(t, "Pearson(inverse)", Pearson(xs, []float64{5, 4, 3, 2, 1}), -1)
+	approx(t, "Pearson(constant)", Pearson(xs, []float64{1, 1, 1, 1, 1}), 0)
+	// monotonic but not linear: Spearman is exactly 1
+	approx(t, "Spearman(monotonic)", Spearman(xs, []float64{1, 8, 27, 64, 125}), 1)
+
+	ranks := Ranks([]float64{10, 20, 20, 30})
+	want := []float64{1, 2.5, 2.5, 4}
+	for i := range want {
+		approx(t, "Ranks", ranks[i], want[i])
+	}
+}
+
+func TestLinearRegression(t *testing.T) {
+	fit := LinearRegression([]float64{0, 1, 2, 3}, []float64{1, 3, 5, 7})
+	approx(t, "Slope", fit.Slope, 2)
+	approx(t, "Intercept", fit.Intercept, 1)
+	approx(t, "R2", fit.R2, 1)
+	approx(t, "Predict", fit.Predict(10), 21)
+
+	flat := LinearRegression([]float64{2, 2, 2}, []float64{1, 2, 3})
+	approx(t, "Slope(vertical)", flat.Slope, 0)
+	approx(t, "Intercept(vertical)", flat.Intercept, 2)
+}
+
+func TestBootstrap(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	xs := make([]float64, 500)
+	for i := range xs {
+		xs[i] = rng.NormFloat64()*2 + 10
+	}
+	ci := Bootstrap(xs, Mean, 2000, 0.95, rand.New(rand.NewSource(2)))
+	if !(ci.Lo < ci.Estimate && ci.Estimate < ci.Hi) {
+		t.Fatalf("estimate %v outside interval [%v, %v]", ci.Estimate, ci.Lo, ci.Hi)
+	}
+	if ci.Lo > 10 || ci.Hi < 10 {
+		t.Errorf("95%% interval [%v, %v] misses the true mean 10", ci.Lo, ci.Hi)
+	}
+	if w := ci.Hi - ci.Lo; w > 1 {
+		t.Errorf("interval width %v too wide for n=500, sd=2", w)
+	}
+	again := Bootstrap(xs, Mean, 2000, 0.95, rand.New(rand.NewSource(2)))
+	if again != ci {
+		t.Error("same seed should reproduce the interval")
+	}
+}
+
+func TestKDE(t *testing.T) {
+	xs := []float64{-1, 0, 0, 1}
+	bw := SilvermanBandwidth(xs)
+	if bw <= 0 {
+		t.Fatalf("bandwidth = %v", bw)
+	}
+	// density integrates to ~1
+	area := 0.0
+	for x := -10.0; x <= 10; x += 0.01 {
+		area += GaussianKDE(xs, bw, x) * 0.01
+	}
+	if math.Abs(area-1) > 1e-3 {
+		t.Errorf("KDE area = %v, want 1", area)
+	}
+	if GaussianKDE(xs, bw, 0) <= GaussianKDE(xs, bw, 3) {
+		t.Error("density should peak near the data")
+	}
+}
@@ -0,0 +1,75 @@
+package stats
+
+import (
+	"math"
+	"sort"
+)
+
+// WeightedMean treats ws as frequency weights: a weight of 3 counts the value
+// three times. Negative weights are not supported by the weighted functions.
+func WeightedMean(xs, ws []float64) float64 {
+	mustSameLen(xs, ws)
+	sw, s := 0.0, 0.0
+	for i, v := range xs {
+		sw += ws[i]
+		s += ws[i] * v
+	}
+	if sw == 0 {
+		return 0
+	}
+	return s / sw
+}
+
+// WeightedVariance is the unbiased variance for frequency weights
+// (Σw - 1 denominator).
+func WeightedVariance(xs, ws []float64) float64 {
+	mustSameLen(xs, ws)
+	sw := 0.0
+	for _, w := range ws {
+		sw += w
+	}
+	if sw <= 1 {
+		return 0
+	}
+	m := WeightedMean(xs, ws)
+	s := 0.0
+	for i, v := range xs {
+		s += ws[i] * (v - m) * (v - m)
+	}
+	return s / (sw - 1)
+}
+
+func WeightedStdDev(xs, ws []float64) float64 { return math.Sqrt(WeightedVariance(xs, ws)) }
+
+// WeightedQuantile returns the smallest value whose cumulative weight reaches
+// q of the total weight.
+func WeightedQuantile(xs, ws []float64, q float64) float64 {
+	mustSameLen(xs, ws)
+	if len(xs) == 0 {
+		return 0
+	}
+	idx := make([]int, len(xs))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool { return xs[idx[a]] < xs[idx[b]] })
+	total := 0.0
+	for _, w := range ws {
+		total += w
+	}
+	target := q * total
+	cum := 0.0
+	for _, i := range idx {
+		cum += ws[i]
+		if cum >= target {
+			return xs[i]
+		}
+	}
+	return xs[idx[len(idx)-1]]
+}
+
+func mustSameLen(a, b []float64) {
+	if len(a) != len(b) {
+		panic("stats: slice length mismatch")
+	}
+}
@@ -1,23 +1,89 @@
 -- name: ListTopProfitableMovies :many
 -- Shows movies with highest revenue and profitability
 SELECT
-    title,
-    budget,
-    revenue,
-    (revenue - budget) as profit,
+    m.title,
+    m.budget,
+    m.revenue,
+    (m.revenue - m.budget) as profit,
     ROUND(
         (
-            revenue::numeric / NULLIF(budget, 0) - 1
+            m.revenue::numeric / NULLIF(m.budget, 0) - 1
         ) * 100,
         2
     ) as roi_percent,
-    vote_average
-FROM movie
+    m.vote_average
+FROM movie m
 WHERE
-    budget > 0
-    AND revenue > 0
+    m.budget > 0
+    AND m.revenue > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 ORDER BY profit DESC
-LIMIT 400;
+LIMIT COALESCE(sqlc.narg(top_n)::int, 400);
+
+-- name: MovieNumericMetrics :many
+-- Raw numeric columns of every movie for distribution charts
+SELECT
+    m.budget,
+    m.revenue,
+    m.runtime,
+    m.vote_average,
+    m.vote_count,
+    m.popularity
+FROM movie m
+WHERE
+    (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int);
 
 -- name: GenreAverageMetrics :many
 -- Analysis of genres by average metrics
@@ -32,10 +98,40 @@ FROM genre g
     JOIN movie m ON mg.movie_id = m.movie_id
 WHERE
     m.vote_average > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
     g.genre_id,
     g.genre_name
-ORDER BY avg_rating DESC;
+HAVING
+    COUNT(m.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 1)
+ORDER BY avg_rating DESC
+LIMIT sqlc.narg(top_n)::int;
 
 -- name: DecadeTrends :many
 -- Number of movies and average metrics by decades
@@ -43,27 +139,50 @@ SELECT
     FLOOR(
         EXTRACT(
             YEAR
-            FROM release_date
+            FROM m.release_date
         ) / 10
     ) * 10 as decade,
     COUNT(*) as movies_count,
-    ROUND(AVG(budget), 0) as avg_budget,
-    ROUND(AVG(revenue), 0) as avg_revenue,
-    ROUND(AVG(vote_average), 2) as avg_rating,
-    ROUND(AVG(runtime), 0) as avg_runtime
-FROM movie
+    ROUND(AVG(m.budget), 0) as avg_budget,
+    ROUND(AVG(m.revenue), 0) as avg_revenue,
+    ROUND(AVG(m.vote_average), 2) as avg_rating,
+    ROUND(AVG(m.runtime), 0) as avg_runtime
+FROM movie m
 
 WHERE
-    release_date IS NOT NULL
-    AND EXTRACT(
-        YEAR
-        FROM release_date
-    ) >= 1970
+    m.release_date IS NOT NULL
+    AND EXTRACT(YEAR FROM m.release_date) >= COALESCE(sqlc.narg(year_from)::int, 1970)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
     FLOOR(
         EXTRACT(
             YEAR
-            FROM release_date
+            FROM m.release_date
         ) / 10
     ) * 10
 ORDER BY decade;
@@ -71,21 +190,122 @@ ORDER BY decade;
 -- name: YearlyTrends :many
 -- Number of movies and average metrics by year
 SELECT
-    EXTRACT(YEAR FROM release_date)::int AS year,
+    EXTRACT(YEAR FROM m.release_date)::int AS year,
     COUNT(*) AS movies_count,
-    ROUND(AVG(budget), 0) AS avg_budget,
-    ROUND(AVG(revenue), 0) AS avg_revenue,
-    ROUND(AVG(vote_average), 2) AS avg_rating,
-    ROUND(AVG(runtime), 0) AS avg_runtime
-FROM movie
+    ROUND(AVG(m.budget), 0) AS avg_budget,
+    ROUND(AVG(m.revenue), 0) AS avg_revenue,
+    ROUND(AVG(m.vote_average), 2) AS avg_rating,
+    ROUND(AVG(m.runtime), 0) AS avg_runtime
+FROM movie m
 WHERE
-    release_date IS NOT NULL
-    AND EXTRACT(YEAR FROM release_date) < 2017
+    m.release_date IS NOT NULL
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(year_from)::int IS NOT NULL OR sqlc.narg(year_to)::int IS NOT NULL OR EXTRACT(YEAR FROM m.release_date) <= 2016)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
-    EXTRACT(YEAR FROM release_date)
+    EXTRACT(YEAR FROM m.release_date)
 ORDER BY year;
 
 
+-- name: MonthlyReleases :many
+-- Number of movies released per month of each year (movies without a release date are excluded)
+SELECT
+    EXTRACT(YEAR FROM m.release_date)::int AS year,
+    EXTRACT(MONTH FROM m.release_date)::int AS month,
+    COUNT(*) AS movies_count
+FROM movie m
+WHERE
+    m.release_date IS NOT NULL
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
+GROUP BY
+    EXTRACT(YEAR FROM m.release_date),
+    EXTRACT(MONTH FROM m.release_date)
+ORDER BY year, month;
+
+-- name: CountUndatedMovies :one
+-- Number of movies without a release date
+SELECT COUNT(*) AS movies_count
+FROM movie m
+WHERE
+    m.release_date IS NULL
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int);
+
+
 -- name: ActorRoleCounts :many
 -- Actors with highest number of roles and average rating of their movies
 SELECT
@@ -98,13 +318,40 @@ FROM person p
     JOIN movie m ON mc.movie_id = m.movie_id
 WHERE
     m.vote_average > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
     p.person_id,
     p.person_name
 HAVING
-    COUNT(mc.movie_id) >= 5
+    COUNT(mc.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 5)
 ORDER BY roles_count DESC, avg_movie_rating DESC
-LIMIT 20;
+LIMIT COALESCE(sqlc.narg(top_n)::int, 20);
 
 -- name: StudioPerformance :many
 -- Top studios by number of movies and average profit
@@ -119,18 +366,46 @@ FROM production_company pc
     JOIN movie m ON mcom.movie_id = m.movie_id
 WHERE
     m.revenue > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
     pc.company_id,
     pc.company_name
 HAVING
-    COUNT(m.movie_id) >= 3
+    COUNT(m.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 3)
 ORDER BY total_revenue DESC
-LIMIT 15;
+LIMIT COALESCE(sqlc.narg(top_n)::int, 15);
 
 -- name: CountryProductionStats :many
 -- Geography of film production and average metrics
 SELECT
     c.country_name,
+    c.country_iso_code,
     COUNT(m.movie_id) as movies_count,
     ROUND(AVG(m.budget), 0) as avg_budget,
     ROUND(AVG(m.revenue), 0) as avg_revenue,
@@ -141,42 +416,99 @@ FROM country c
 WHERE
     m.budget > 0
     AND m.revenue > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
     c.country_id,
-    c.country_name
+    c.country_name,
+    c.country_iso_code
 HAVING
-    COUNT(m.movie_id) >= 10
-ORDER BY movies_count DESC;
+    COUNT(m.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 10)
+ORDER BY movies_count DESC
+LIMIT sqlc.narg(top_n)::int;
 
 -- name: RuntimeSuccessSegments :many
 -- TOPIC 7: MOVIE DURATION AND COMMERCIAL SUCCESS
 SELECT
     CASE
-        WHEN runtime < 90 THEN 'Short (<90 min)'
-        WHEN runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
-        WHEN runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
+        WHEN m.runtime < 90 THEN 'Short (<90 min)'
+        WHEN m.runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
+        WHEN m.runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
         ELSE 'Very long (>150 min)'
     END as duration_category,
     COUNT(*) as movies_count,
-    ROUND(AVG(revenue), 0) as avg_revenue,
-    ROUND(AVG(vote_average), 2) as avg_rating,
-    ROUND(AVG(popularity), 2) as avg_popularity
-FROM movie
+    ROUND(AVG(m.revenue), 0) as avg_revenue,
+    ROUND(AVG(m.vote_average), 2) as avg_rating,
+    ROUND(AVG(m.popularity), 2) as avg_popularity
+FROM movie m
 WHERE
-    runtime IS NOT NULL
-    AND runtime > 0
+    m.runtime IS NOT NULL
+    AND m.runtime > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
     CASE
-        WHEN runtime < 90 THEN 'Short (<90 min)'
-        WHEN runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
-        WHEN runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
+        WHEN m.runtime < 90 THEN 'Short (<90 min)'
+        WHEN m.runtime BETWEEN 90 AND 120  THEN 'Medium (90-120 min)'
+        WHEN m.runtime BETWEEN 121 AND 150  THEN 'Long (121-150 min)'
         ELSE 'Very long (>150 min)'
     END
 ORDER BY avg_revenue DESC;
 
 -- name: LanguagePopularity :many
--- Analysis of original movie languages
+-- Analysis of movie languages by role (original or spoken)
 SELECT
+    lr.language_role,
     l.language_name,
     COUNT(m.movie_id) as movies_count,
     ROUND(AVG(m.vote_average), 2) as avg_rating,
@@ -185,14 +517,45 @@ SELECT
 FROM language l
     JOIN movie_languages ml ON l.language_id = ml.language_id
     JOIN movie m ON ml.movie_id = m.movie_id
+    LEFT JOIN language_role lr ON ml.language_role_id = lr.role_id
 WHERE
     m.vote_average > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
+    lr.role_id,
+    lr.language_role,
     l.language_id,
     l.language_name
 HAVING
-    COUNT(m.movie_id) >= 5
-ORDER BY movies_count DESC;
+    COUNT(m.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 5)
+ORDER BY movies_count DESC, lr.role_id
+LIMIT sqlc.narg(top_n)::int;
 
 -- name: KeywordTrends :many
 -- TOPIC 9: KEYWORDS AND TRENDS
@@ -206,13 +569,111 @@ FROM keyword k
     JOIN movie m ON mk.movie_id = m.movie_id
 WHERE
     m.vote_average > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
+    AND (sqlc.narg(stop_keywords)::text[] IS NULL OR lower(k.keyword_name) <> ALL(sqlc.narg(stop_keywords)::text[]))
 GROUP BY
     k.keyword_id,
     k.keyword_name
 HAVING
-    COUNT(m.movie_id) >= 10
+    COUNT(m.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 10)
 ORDER BY movies_count DESC, avg_rating DESC
-LIMIT 20;
+LIMIT COALESCE(sqlc.narg(top_n)::int, 20);
+
+-- name: KeywordYearlyTrends :many
+-- Movies per year for each of the most frequent keywords (movies without a release date are excluded)
+WITH filtered AS (
+    SELECT
+        m.movie_id,
+        EXTRACT(YEAR FROM m.release_date)::int AS year
+    FROM movie m
+    WHERE
+        m.release_date IS NOT NULL
+        AND m.vote_average > 0
+        AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+        AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+        AND (sqlc.narg(year_from)::int IS NOT NULL OR sqlc.narg(year_to)::int IS NOT NULL OR EXTRACT(YEAR FROM m.release_date) <= 2016)
+        AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+            SELECT 1
+            FROM movie_genres f_mg
+                JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+            WHERE
+                f_mg.movie_id = m.movie_id
+                AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+        ))
+        AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+            SELECT 1
+            FROM production_country f_pc
+                JOIN country f_c ON f_pc.country_id = f_c.country_id
+            WHERE
+                f_pc.movie_id = m.movie_id
+                AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+        ))
+        AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+            SELECT 1
+            FROM movie_languages f_ml
+                JOIN language f_l ON f_ml.language_id = f_l.language_id
+            WHERE
+                f_ml.movie_id = m.movie_id
+                AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+        ))
+        AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
+),
+top_keywords AS (
+    SELECT
+        k.keyword_id,
+        k.keyword_name
+    FROM keyword k
+        JOIN movie_keywords mk ON k.keyword_id = mk.keyword_id
+        JOIN filtered f ON mk.movie_id = f.movie_id
+    WHERE
+        sqlc.narg(stop_keywords)::text[] IS NULL
+        OR lower(k.keyword_name) <> ALL(sqlc.narg(stop_keywords)::text[])
+    GROUP BY
+        k.keyword_id,
+        k.keyword_name
+    HAVING
+        COUNT(f.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 10)
+    ORDER BY COUNT(f.movie_id) DESC, k.keyword_name
+    LIMIT COALESCE(sqlc.narg(top_n)::int, 10)
+)
+SELECT
+    f.year,
+    tk.keyword_name,
+    COUNT(f.movie_id) AS movies_count
+FROM top_keywords tk
+    JOIN movie_keywords mk ON tk.keyword_id = mk.keyword_id
+    JOIN filtered f ON mk.movie_id = f.movie_id
+GROUP BY
+    f.year,
+    tk.keyword_id,
+    tk.keyword_name
+ORDER BY f.year, movies_count DESC;
 
 -- name: DirectorPerformance :many
 -- Top directors by average metrics of their movies
@@ -232,10 +693,37 @@ WHERE
     AND mc.job = 'Director'
     AND m.revenue > 0
     AND m.vote_average > 0
+    AND (sqlc.narg(year_from)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) >= sqlc.narg(year_from)::int)
+    AND (sqlc.narg(year_to)::int IS NULL OR EXTRACT(YEAR FROM m.release_date) <= sqlc.narg(year_to)::int)
+    AND (sqlc.narg(genre)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_genres f_mg
+            JOIN genre f_g ON f_mg.genre_id = f_g.genre_id
+        WHERE
+            f_mg.movie_id = m.movie_id
+            AND lower(f_g.genre_name) = lower(sqlc.narg(genre)::text)
+    ))
+    AND (sqlc.narg(country)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM production_country f_pc
+            JOIN country f_c ON f_pc.country_id = f_c.country_id
+        WHERE
+            f_pc.movie_id = m.movie_id
+            AND lower(sqlc.narg(country)::text) IN (lower(f_c.country_name), lower(f_c.country_iso_code))
+    ))
+    AND (sqlc.narg(language)::text IS NULL OR EXISTS (
+        SELECT 1
+        FROM movie_languages f_ml
+            JOIN language f_l ON f_ml.language_id = f_l.language_id
+        WHERE
+            f_ml.movie_id = m.movie_id
+            AND lower(sqlc.narg(language)::text) IN (lower(f_l.language_name), lower(f_l.language_code))
+    ))
+    AND (sqlc.narg(min_vote_count)::int IS NULL OR m.vote_count >= sqlc.narg(min_vote_count)::int)
 GROUP BY
     p.person_id,
     p.person_name
 HAVING
-    COUNT(m.movie_id) >= 3
+    COUNT(m.movie_id) >= COALESCE(sqlc.narg(min_count)::int, 3)
 ORDER BY avg_rating DESC, total_box_office DESC
-LIMIT 15;
+LIMIT COALESCE(sqlc.narg(top_n)::int, 15);
@@ -0,0 +1,22 @@
+#!/bin/sh
+# Downloads the ECharts bundles referenced by go-echarts, and the country
+# boundaries drawn by the world map chart, into internal/assets/vendor so charts
+# can be rendered for offline use.
+set -eu
+
+HOST="${ASSETS_HOST:-https://go-echarts.github.io/go-echarts-assets/assets}"
+COUNTRIES="${COUNTRIES_URL:-https://raw.githubusercontent.com/nvkelso/natural-earth-vector/master/geojson/ne_110m_admin_0_countries.geojson}"
+DEST="${1:-$(dirname "$0")/../internal/assets/vendor}"
+
+for f in echarts.min.js echarts-wordcloud.min.js maps/world.js; do
+    mkdir -p "$DEST/$(dirname "$f")"
+    echo "fetching $f"
+    curl -fsSL "$HOST/$f" -o "$DEST/$f"
+done
+
+# the boundaries are committed; only fetch them into a fresh destination
+if [ ! -f "$DEST/geo/countries.geojson" ]; then
+    mkdir -p "$DEST/geo"
+    echo "fetching geo/countries.geojson"
+    curl -fsSL "$COUNTRIES" -o "$DEST/geo/countries.geojson"
+fi